@@ -0,0 +1,261 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+// method is one interface method prepared for the templates.
+type method struct {
+	Name      string // Go name
+	Wire      string // name on the wire, e.g. "payment.process"
+	Param     string // param type, "" if the method takes only ctx
+	Result    string // result type, "" if the method returns only error
+	ConstName string
+}
+
+type model struct {
+	Package    string
+	Type       string
+	StdImports []string // standard library imports of the param/result types
+	Imports    []string // all other imports of the param/result types
+	Methods    []method
+	Partial    bool // unexported methods were skipped, the client cannot implement Type
+}
+
+// Generate parses src, looks up the interface typeName and returns
+// the formatted source of the stubs.
+func Generate(src, typeName, namespace string, naming rpc.NamingFunc) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, src, nil, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	iface := findInterface(file, typeName)
+	if iface == nil {
+		return nil, fmt.Errorf("interface %s not found in %s", typeName, src)
+	}
+
+	m := model{Package: file.Name.Name, Type: typeName}
+	used := make(map[string]bool)
+
+	for _, field := range iface.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) == 0 {
+			return nil, fmt.Errorf("%s: embedded interfaces are not supported", fset.Position(field.Pos()))
+		}
+		name := field.Names[0].Name
+		if !ast.IsExported(name) {
+			m.Partial = true
+			continue
+		}
+
+		md, err := describe(fset, name, fn, used)
+		if err != nil {
+			return nil, err
+		}
+		md.Wire = naming(namespace, name)
+		md.ConstName = typeName + name + "Method"
+		m.Methods = append(m.Methods, md)
+	}
+
+	m.StdImports, m.Imports = imports(file, used)
+
+	var buf bytes.Buffer
+	if err := stubTemplate.Execute(&buf, m); err != nil {
+		return nil, err
+	}
+	code, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %w", err)
+	}
+	return code, nil
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	var found *ast.InterfaceType
+	ast.Inspect(file, func(n ast.Node) bool {
+		ts, ok := n.(*ast.TypeSpec)
+		if !ok || ts.Name.Name != name {
+			return found == nil
+		}
+		found, _ = ts.Type.(*ast.InterfaceType)
+		return false
+	})
+	return found
+}
+
+// describe checks the signature Method(ctx context.Context[, P]) ([R, ]error).
+func describe(fset *token.FileSet, name string, fn *ast.FuncType, used map[string]bool) (method, error) {
+	pos := fset.Position(fn.Pos())
+	md := method{Name: name}
+
+	params := flatten(fn.Params)
+	if len(params) < 1 || len(params) > 2 || expr(fset, params[0]) != "context.Context" {
+		return md, fmt.Errorf("%s: %s must take (context.Context[, P])", pos, name)
+	}
+	if len(params) == 2 {
+		md.Param = expr(fset, params[1])
+		collect(params[1], used)
+	}
+
+	results := flatten(fn.Results)
+	if len(results) < 1 || len(results) > 2 || expr(fset, results[len(results)-1]) != "error" {
+		return md, fmt.Errorf("%s: %s must return ([R, ]error)", pos, name)
+	}
+	if len(results) == 2 {
+		md.Result = expr(fset, results[0])
+		collect(results[0], used)
+	}
+	return md, nil
+}
+
+// flatten expands "a, b T" into one entry per parameter.
+func flatten(fl *ast.FieldList) []ast.Expr {
+	if fl == nil {
+		return nil
+	}
+	var out []ast.Expr
+	for _, f := range fl.List {
+		n := len(f.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			out = append(out, f.Type)
+		}
+	}
+	return out
+}
+
+func expr(fset *token.FileSet, e ast.Expr) string {
+	var buf bytes.Buffer
+	format.Node(&buf, fset, e)
+	return buf.String()
+}
+
+// collect remembers package qualifiers used by a type expression.
+func collect(e ast.Expr, used map[string]bool) {
+	ast.Inspect(e, func(n ast.Node) bool {
+		if sel, ok := n.(*ast.SelectorExpr); ok {
+			if id, ok := sel.X.(*ast.Ident); ok {
+				used[id.Name] = true
+			}
+		}
+		return true
+	})
+}
+
+// imports returns the import specs of file that the stubs need,
+// split into standard library and other packages.
+func imports(file *ast.File, used map[string]bool) (std, other []string) {
+	for _, spec := range file.Imports {
+		p, _ := strconv.Unquote(spec.Path.Value)
+		name := path.Base(p)
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if !used[name] || p == "context" || p == "encoding/json" {
+			continue
+		}
+		imp := strconv.Quote(p)
+		if spec.Name != nil {
+			imp = spec.Name.Name + " " + imp
+		}
+		// Standard library paths have no dot in their first element.
+		if first, _, _ := strings.Cut(p, "/"); strings.Contains(first, ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	return std, other
+}
+
+var stubTemplate = template.Must(template.New("stub").Parse(`// Code generated by nexio-gen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"encoding/json"
+{{range .StdImports}}	{{.}}
+{{end}}
+{{- if .Imports}}
+{{range .Imports}}	{{.}}
+{{end}}{{end}}
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+// Wire names of the {{.Type}} methods.
+const (
+{{range .Methods}}	{{.ConstName}} = "{{.Wire}}"
+{{end}})
+
+// {{.Type}}Client calls a remote {{.Type}} through an rpc.Node.
+{{- if .Partial}}
+// It covers only the exported methods and does not implement {{.Type}}.
+{{- end}}
+type {{.Type}}Client struct {
+	Node *rpc.Node
+}
+{{if not .Partial}}
+var _ {{.Type}} = (*{{.Type}}Client)(nil)
+{{end}}
+func New{{.Type}}Client(node *rpc.Node) *{{.Type}}Client {
+	return &{{.Type}}Client{Node: node}
+}
+{{range .Methods}}{{$m := .}}
+func (c *{{$.Type}}Client) {{.Name}}(ctx context.Context{{if .Param}}, p {{.Param}}{{end}}) ({{if .Result}}{{.Result}}, {{end}}error) {
+{{- if .Result}}
+	var out {{.Result}}
+	raw, err := c.Node.Call(ctx, {{.ConstName}}, {{if .Param}}p{{else}}nil{{end}})
+	if err != nil {
+		return out, err
+	}
+	if len(raw) > 0 {
+		err = json.Unmarshal(raw, &out)
+	}
+	return out, err
+{{- else}}
+	_, err := c.Node.Call(ctx, {{.ConstName}}, {{if .Param}}p{{else}}nil{{end}})
+	return err
+{{- end}}
+}
+{{end}}
+// Register{{.Type}} binds impl onto node.
+func Register{{.Type}}(node *rpc.Node, impl {{.Type}}) {
+{{- range .Methods}}
+	node.Register({{.ConstName}}, func(ctx context.Context, params json.RawMessage) (any, error) {
+{{- if .Param}}
+		p, err := rpc.Bind[{{.Param}}](params)
+		if err != nil {
+			return nil, err
+		}
+{{- end}}
+{{- if .Result}}
+		return impl.{{.Name}}(ctx{{if .Param}}, p{{end}})
+{{- else}}
+		return nil, impl.{{.Name}}(ctx{{if .Param}}, p{{end}})
+{{- end}}
+	})
+{{- end}}
+}
+`))
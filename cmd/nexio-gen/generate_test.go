@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestGenerate_Golden(t *testing.T) {
+	for _, tc := range []struct{ typ, golden string }{
+		{"BillingService", "service_nexio.go.golden"},
+		{"LedgerService", "ledger_nexio.go.golden"}, // unexported method
+	} {
+		t.Run(tc.typ, func(t *testing.T) {
+			got, err := Generate(filepath.Join("testdata", "service.go"), tc.typ, "billing", rpc.NameLowerCamel)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(golden, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Generated code differs from %s (run with -update to accept):\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "bad.go")
+	os.WriteFile(src, []byte(`package bad
+
+import "context"
+
+type NoCtx interface{ Do(id string) error }
+type NoErr interface{ Do(ctx context.Context) string }
+`), 0o644)
+
+	for _, typ := range []string{"NoCtx", "NoErr", "Missing"} {
+		if _, err := Generate(src, typ, "bad", rpc.NameExact); err == nil {
+			t.Errorf("Expected error for %s", typ)
+		}
+	}
+}
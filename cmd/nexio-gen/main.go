@@ -0,0 +1,76 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+// nexio-gen generates typed client stubs and registration helpers
+// for rpc.Node from a Go interface.
+//
+// Usage (typically via go:generate):
+//
+//	//go:generate go run github.com/georghagn/nexio/cmd/nexio-gen -type PaymentService -namespace payment
+//
+// For every method of the form
+//
+//	Method(ctx context.Context[, p P]) ([R, ]error)
+//
+// it emits a <Type>Client that wraps node.Call and a Register<Type>
+// function that binds an implementation onto node.Register.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the interface (required)")
+	namespace := flag.String("namespace", "", "method namespace (default: lower-cased type without \"Service\")")
+	naming := flag.String("naming", "lowerCamel", "method naming: exact, lowerCamel or snake")
+	src := flag.String("src", os.Getenv("GOFILE"), "source file containing the interface")
+	out := flag.String("o", "", "output file (default: <src>_nexio.go)")
+	flag.Parse()
+
+	if *typeName == "" || *src == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	nameFn, err := namingFunc(*naming)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ns := *namespace
+	if ns == "" {
+		ns = strings.ToLower(strings.TrimSuffix(*typeName, "Service"))
+	}
+
+	code, err := Generate(*src, *typeName, ns, nameFn)
+	if err != nil {
+		log.Fatalf("nexio-gen: %v", err)
+	}
+
+	target := *out
+	if target == "" {
+		target = strings.TrimSuffix(*src, ".go") + "_nexio.go"
+	}
+	if err := os.WriteFile(target, code, 0o644); err != nil {
+		log.Fatalf("nexio-gen: %v", err)
+	}
+}
+
+func namingFunc(name string) (rpc.NamingFunc, error) {
+	switch name {
+	case "exact":
+		return rpc.NameExact, nil
+	case "lowerCamel":
+		return rpc.NameLowerCamel, nil
+	case "snake":
+		return rpc.NameSnake, nil
+	}
+	return nil, fmt.Errorf("unknown naming %q (exact, lowerCamel, snake)", name)
+}
@@ -0,0 +1,31 @@
+package billing
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	money "example.com/money/v2"
+)
+
+type Invoice struct {
+	ID     string
+	Amount money.Amount
+	Due    time.Time
+	Header http.Header // not in a signature, so not imported by the stubs
+}
+
+type BillingService interface {
+	Ping(ctx context.Context) error
+	Create(ctx context.Context, inv Invoice) (string, error)
+	Due(ctx context.Context) ([]time.Time, error)
+	Total(ctx context.Context, ids []string) (money.Amount, error)
+	Cancel(ctx context.Context, id string) error
+}
+
+// LedgerService has an unexported method, so its client cannot
+// implement it.
+type LedgerService interface {
+	Post(ctx context.Context, inv Invoice) error
+	audit() []string
+}
@@ -0,0 +1,175 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+	"github.com/georghagn/nexio/node/transport/middleware"
+)
+
+// frame is a recorded frame with the JSON-RPC fields we care about.
+type frame struct {
+	middleware.Frame
+	Offset time.Duration
+	Size   int
+	Kind   string // request, notification, response, error, binary, invalid
+	Method string // for responses: method of the matching request
+	ID     string
+	Err    string
+}
+
+func describe(recorded []middleware.Frame) []frame {
+	var start time.Time
+	if len(recorded) > 0 {
+		start = recorded[0].T
+	}
+	// Requests by direction and id, to name responses.
+	methods := map[string]string{}
+
+	out := make([]frame, 0, len(recorded))
+	for _, r := range recorded {
+		data := r.Data()
+		f := frame{Frame: r, Offset: r.T.Sub(start), Size: len(data)}
+
+		var msg struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+			Error  *struct {
+				Code    int    `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		switch {
+		case transport.IsBinaryFrame(data):
+			f.Kind = "binary"
+		case json.Unmarshal(data, &msg) != nil:
+			f.Kind = "invalid"
+		case msg.Method != "":
+			f.Method = msg.Method
+			f.ID = string(msg.ID)
+			f.Kind = "request"
+			if f.ID == "" || f.ID == "null" {
+				f.Kind, f.ID = "notification", ""
+			} else {
+				methods[r.Dir+f.ID] = msg.Method
+			}
+		default:
+			f.ID = string(msg.ID)
+			f.Kind = "response"
+			// The request went the other way.
+			other := middleware.DirOut
+			if r.Dir == middleware.DirOut {
+				other = middleware.DirIn
+			}
+			f.Method = methods[other+f.ID]
+			if msg.Error != nil {
+				f.Kind = "error"
+				f.Err = fmt.Sprintf("%d %s", msg.Error.Code, msg.Error.Message)
+			}
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+func inspect(w io.Writer, frames []frame, raw bool, method string) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	if !raw {
+		fmt.Fprintln(tw, "#\tOFFSET\tDIR\tBYTES\tKIND\tID\tMETHOD\tERROR")
+	}
+	for i, f := range frames {
+		if method != "" {
+			if ok, _ := path.Match(method, f.Method); !ok {
+				continue
+			}
+		}
+		if raw {
+			line, _ := json.Marshal(f.Frame)
+			fmt.Fprintf(w, "%s\n", line)
+			continue
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			i, f.Offset.Round(time.Microsecond), f.Dir, f.Size, f.Kind, f.ID, f.Method, f.Err)
+	}
+}
+
+type methodStats struct {
+	calls, notifications, errors, open int
+	latency                            []time.Duration
+}
+
+func summary(w io.Writer, frames []frame) {
+	stats := map[string]*methodStats{}
+	get := func(m string) *methodStats {
+		if stats[m] == nil {
+			stats[m] = &methodStats{}
+		}
+		return stats[m]
+	}
+
+	sent := map[string]frame{} // dir+id of open requests
+	for _, f := range frames {
+		switch f.Kind {
+		case "request":
+			get(f.Method).calls++
+			sent[f.Dir+f.ID] = f
+		case "notification":
+			get(f.Method).notifications++
+		case "response", "error":
+			other := middleware.DirOut
+			if f.Dir == middleware.DirOut {
+				other = middleware.DirIn
+			}
+			req, ok := sent[other+f.ID]
+			if !ok {
+				continue
+			}
+			delete(sent, other+f.ID)
+			s := get(req.Method)
+			s.latency = append(s.latency, f.T.Sub(req.T))
+			if f.Kind == "error" {
+				s.errors++
+			}
+		}
+	}
+	for _, req := range sent {
+		get(req.Method).open++
+	}
+
+	names := make([]string, 0, len(stats))
+	for m := range stats {
+		names = append(names, m)
+	}
+	sort.Strings(names)
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "%d frames\n\n", len(frames))
+	fmt.Fprintln(tw, "METHOD\tCALLS\tNOTIFY\tERRORS\tNO RESPONSE\tAVG\tMAX")
+	for _, m := range names {
+		s := stats[m]
+		var sum, max time.Duration
+		for _, d := range s.latency {
+			sum += d
+			if d > max {
+				max = d
+			}
+		}
+		avg := time.Duration(0)
+		if len(s.latency) > 0 {
+			avg = sum / time.Duration(len(s.latency))
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\t%s\n",
+			m, s.calls, s.notifications, s.errors, s.open, avg.Round(time.Microsecond), max.Round(time.Microsecond))
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+// nexio-replay inspects session recordings made with replay.Record or
+// middleware.Record.
+//
+// Usage:
+//
+//	nexio-replay inspect [-raw] [-method pattern] session.jsonl
+//	nexio-replay summary session.jsonl
+//
+// inspect prints one line per frame with the offset from the first frame,
+// direction, size and a short description; -raw prints the recorded lines.
+// summary counts calls per method and reports calls without a response
+// and the response latency.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+
+	"github.com/georghagn/nexio/node/replay"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "inspect":
+		fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+		raw := fs.Bool("raw", false, "print frames as recorded")
+		method := fs.String("method", "", "only frames of matching methods (path.Match pattern)")
+		fs.Parse(os.Args[2:])
+		if fs.NArg() != 1 {
+			usage()
+		}
+		if _, err := path.Match(*method, ""); err != nil {
+			log.Fatalf("nexio-replay: bad pattern: %v", err)
+		}
+		run(fs.Arg(0), func(w io.Writer, frames []frame) { inspect(w, frames, *raw, *method) })
+	case "summary":
+		if len(os.Args) != 3 {
+			usage()
+		}
+		run(os.Args[2], summary)
+	default:
+		usage()
+	}
+}
+
+func run(file string, fn func(io.Writer, []frame)) {
+	recorded, err := replay.LoadFile(file)
+	if err != nil {
+		log.Fatalf("nexio-replay: %v", err)
+	}
+	fn(os.Stdout, describe(recorded))
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: nexio-replay inspect [-raw] [-method pattern] <file>")
+	fmt.Fprintln(os.Stderr, "       nexio-replay summary <file>")
+	os.Exit(2)
+}
@@ -0,0 +1,304 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"text/tabwriter"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// benchMethod is served by the built-in echo server.
+const benchMethod = "bench.echo"
+
+type benchConfig struct {
+	url         string
+	method      string
+	conns       int
+	concurrency int
+	rate        float64
+	size        int
+	duration    time.Duration
+	requests    int
+	timeout     time.Duration
+}
+
+func runBench(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	var cfg benchConfig
+	fs.StringVar(&cfg.url, "url", "ws://localhost:8080/ws", "peer to load")
+	fs.StringVar(&cfg.method, "method", benchMethod, "method to call; params are a string of -size bytes")
+	fs.IntVar(&cfg.conns, "conns", 1, "number of connections")
+	fs.IntVar(&cfg.concurrency, "c", 8, "concurrent calls over all connections")
+	fs.Float64Var(&cfg.rate, "rate", 0, "target calls per second over all workers (0 = as fast as possible)")
+	fs.IntVar(&cfg.size, "size", 64, "payload size in bytes")
+	fs.DurationVar(&cfg.duration, "d", 10*time.Second, "test duration")
+	fs.IntVar(&cfg.requests, "n", 0, "stop after n calls instead of -d")
+	fs.DurationVar(&cfg.timeout, "timeout", 5*time.Second, "timeout per call")
+	serve := fs.String("serve", "", "only run the echo server on this address (e.g. :8080)")
+	local := fs.Bool("local", false, "start an echo server on localhost and load it")
+	if len(parseArgs(fs, args)) > 0 {
+		return fmt.Errorf("usage: nexio bench [flags]")
+	}
+
+	if *serve != "" {
+		l, err := net.Listen("tcp", *serve)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "echo server on %s (method %s)\n", l.Addr(), benchMethod)
+		return serveEcho(ctx, l)
+	}
+	if *local {
+		// The listener stays bound, so the first dial cannot race the server.
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			return err
+		}
+		go serveEcho(ctx, l)
+		cfg.url = "ws://" + l.Addr().String() + "/ws"
+	}
+	if cfg.conns < 1 || cfg.concurrency < 1 {
+		return errors.New("-conns and -c must be at least 1")
+	}
+
+	res, err := bench(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	res.report(os.Stdout, cfg)
+	return nil
+}
+
+// serveEcho answers benchMethod with its params on l until ctx ends.
+func serveEcho(ctx context.Context, l net.Listener) error {
+	provider := transport.NewWSProvider(nil)
+	provider.ReadLimit = 64 << 20
+	found := make(chan transport.Connection)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := rpc.NewNode(conn, nil, "", nil)
+				node.Register(benchMethod, func(ctx context.Context, p json.RawMessage) (any, error) {
+					return p, nil
+				})
+				node.EnableIntrospection()
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	if err := provider.Serve(ctx, l, found); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+type benchResult struct {
+	elapsed    time.Duration
+	latencies  []time.Duration // successful calls
+	errors     map[string]int
+	reconnects int64
+}
+
+func bench(ctx context.Context, cfg benchConfig) (*benchResult, error) {
+	provider := transport.NewWSProvider(nil)
+	provider.ReadLimit = 64 << 20
+
+	// Connect all Nodes first; every later connect is a reconnect.
+	var connects atomic.Int64
+	nodes := make([]*rpc.Node, cfg.conns)
+	for i := range nodes {
+		node := rpc.NewNode(nil, provider, cfg.url, nil)
+		node.SetReconnectBackoff(100*time.Millisecond, time.Second)
+		ready := make(chan struct{})
+		var once sync.Once
+		node.OnConnect(func(context.Context) {
+			connects.Add(1)
+			once.Do(func() { close(ready) })
+		})
+		go node.Listen(ctx)
+
+		select {
+		case <-ready:
+		case <-time.After(cfg.timeout):
+			return nil, fmt.Errorf("connection %d to %s: timeout", i+1, cfg.url)
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		nodes[i] = node
+	}
+	initial := connects.Load()
+
+	payload := strings.Repeat("x", cfg.size)
+	runCtx, stop := context.WithCancel(ctx)
+	defer stop()
+	if cfg.requests == 0 {
+		runCtx, stop = context.WithTimeout(ctx, cfg.duration)
+		defer stop()
+	}
+
+	// A shared ticker paces all workers when -rate is set.
+	var tokens <-chan time.Time
+	if cfg.rate > 0 {
+		t := time.NewTicker(time.Duration(float64(time.Second) / cfg.rate))
+		defer t.Stop()
+		tokens = t.C
+	}
+
+	var issued atomic.Int64
+	var mu sync.Mutex
+	res := &benchResult{errors: make(map[string]int)}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.concurrency; w++ {
+		wg.Add(1)
+		go func(node *rpc.Node) {
+			defer wg.Done()
+			var lat []time.Duration
+			errs := make(map[string]int)
+			defer func() {
+				mu.Lock()
+				res.latencies = append(res.latencies, lat...)
+				for k, v := range errs {
+					res.errors[k] += v
+				}
+				mu.Unlock()
+			}()
+
+			for {
+				if tokens != nil {
+					select {
+					case <-tokens:
+					case <-runCtx.Done():
+						return
+					}
+				}
+				if runCtx.Err() != nil || (cfg.requests > 0 && issued.Add(1) > int64(cfg.requests)) {
+					return
+				}
+
+				cctx, cancel := context.WithTimeout(runCtx, cfg.timeout)
+				t0 := time.Now()
+				_, err := node.Call(cctx, cfg.method, payload)
+				d := time.Since(t0)
+				cancel()
+
+				switch {
+				case err == nil:
+					lat = append(lat, d)
+				case runCtx.Err() != nil:
+					return // the test ended during this call
+				default:
+					errs[errorClass(err)]++
+				}
+			}
+		}(nodes[w%len(nodes)])
+	}
+	wg.Wait()
+
+	res.elapsed = time.Since(start)
+	res.reconnects = connects.Load() - initial
+	return res, nil
+}
+
+// errorClass groups errors for the report.
+func errorClass(err error) string {
+	var rpcErr *rpc.RPCError
+	switch {
+	case errors.As(err, &rpcErr):
+		return "rpc " + strconv.Itoa(rpcErr.Code) + " " + rpcErr.Message
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	}
+	return "transport: " + err.Error()
+}
+
+func (r *benchResult) report(w io.Writer, cfg benchConfig) {
+	sort.Slice(r.latencies, func(i, j int) bool { return r.latencies[i] < r.latencies[j] })
+	ok := len(r.latencies)
+	failed := 0
+	for _, n := range r.errors {
+		failed += n
+	}
+	total := ok + failed
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	defer tw.Flush()
+	fmt.Fprintf(tw, "target\t%s %s, %d B payload\n", cfg.url, cfg.method, cfg.size)
+	fmt.Fprintf(tw, "load\t%d connections, %d concurrent calls\n", cfg.conns, cfg.concurrency)
+	fmt.Fprintf(tw, "calls\t%d in %s (%d ok, %d failed, %.2f%% errors)\n",
+		total, r.elapsed.Round(time.Millisecond), ok, failed, percent(failed, total))
+	fmt.Fprintf(tw, "throughput\t%.1f calls/s\n", float64(ok)/r.elapsed.Seconds())
+	fmt.Fprintf(tw, "reconnects\t%d\n", r.reconnects)
+
+	if ok > 0 {
+		var sum time.Duration
+		for _, d := range r.latencies {
+			sum += d
+		}
+		fmt.Fprintf(tw, "latency\tmin %s\tmean %s\tmax %s\n",
+			fmtDur(r.latencies[0]), fmtDur(sum/time.Duration(ok)), fmtDur(r.latencies[ok-1]))
+		fmt.Fprintf(tw, "\tp50 %s\tp90 %s\tp99 %s\tp99.9 %s\n",
+			fmtDur(percentile(r.latencies, 50)), fmtDur(percentile(r.latencies, 90)),
+			fmtDur(percentile(r.latencies, 99)), fmtDur(percentile(r.latencies, 99.9)))
+	}
+
+	classes := make([]string, 0, len(r.errors))
+	for k := range r.errors {
+		classes = append(classes, k)
+	}
+	sort.Strings(classes)
+	for _, k := range classes {
+		fmt.Fprintf(tw, "error\t%d × %s\n", r.errors[k], k)
+	}
+}
+
+// percentile uses the nearest-rank method on sorted durations:
+// the smallest value with at least p percent of all values at or below it.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	// The epsilon keeps float noise (e.g. 99.9% of 1000) from adding a rank.
+	rank := int(math.Ceil(p/100*float64(len(sorted))-1e-9)) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	if rank >= len(sorted) {
+		rank = len(sorted) - 1
+	}
+	return sorted[rank]
+}
+
+func percent(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return 100 * float64(n) / float64(total)
+}
+
+func fmtDur(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
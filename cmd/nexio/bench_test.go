@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentile(t *testing.T) {
+	ms := func(n int) []time.Duration {
+		out := make([]time.Duration, n)
+		for i := range out {
+			out[i] = time.Duration(i+1) * time.Millisecond
+		}
+		return out
+	}
+
+	cases := []struct {
+		name   string
+		sorted []time.Duration
+		p      float64
+		want   time.Duration
+	}{
+		{"empty", nil, 50, 0},
+		{"single", ms(1), 99.9, 1 * time.Millisecond},
+		{"p0 is the minimum", ms(10), 0, 1 * time.Millisecond},
+		{"p50 of 10", ms(10), 50, 5 * time.Millisecond},
+		{"p90 of 10", ms(10), 90, 9 * time.Millisecond},
+		{"p99 of 10 rounds up", ms(10), 99, 10 * time.Millisecond},
+		{"p50 of 5", ms(5), 50, 3 * time.Millisecond},
+		{"p99.9 of 1000", ms(1000), 99.9, 999 * time.Millisecond},
+		{"p100 is the maximum", ms(1000), 100, 1000 * time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := percentile(c.sorted, c.p); got != c.want {
+			t.Errorf("%s: percentile(%v) = %s, want %s", c.name, c.p, got, c.want)
+		}
+	}
+}
+
+func TestReport(t *testing.T) {
+	r := &benchResult{
+		elapsed:   time.Second,
+		latencies: []time.Duration{3 * time.Millisecond, time.Millisecond, 2 * time.Millisecond},
+		errors:    map[string]int{"timeout": 1},
+	}
+	var buf bytes.Buffer
+	r.report(&buf, benchConfig{url: "ws://x/ws", method: benchMethod, conns: 1, concurrency: 2, size: 8})
+	out := buf.String()
+
+	for _, want := range []string{
+		"4 in 1s (3 ok, 1 failed, 25.00% errors)",
+		"3.0 calls/s",
+		"min 1ms",
+		"max 3ms",
+		"p50 2ms",
+		"1 × timeout",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Report lacks %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestBenchLocal(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go serveEcho(ctx, l)
+
+	// Dialing right away must not race the server start.
+	res, err := bench(ctx, benchConfig{
+		url: "ws://" + l.Addr().String() + "/ws", method: benchMethod,
+		conns: 2, concurrency: 4, size: 16, requests: 40, timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.latencies) != 40 || len(res.errors) != 0 {
+		t.Errorf("Expected 40 successful calls, got %d (errors %v)", len(res.latencies), res.errors)
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/georghagn/nexio/node/pubsub"
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+func runCall(ctx context.Context, args []string, notify bool) error {
+	name := "call"
+	if notify {
+		name = "notify"
+	}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	var cf connFlags
+	var pairs multiFlag
+	cf.register(fs)
+	fs.Var(&pairs, "p", "param key=value (repeatable, builds an object)")
+	pos := parseArgs(fs, args)
+
+	if len(pos) < 1 || len(pos) > 2 {
+		return fmt.Errorf("usage: nexio %s [flags] <method> [params|-]", name)
+	}
+	pos = append(pos, "")
+	method := pos[0]
+	params, err := parseParams(pos[1], pairs, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	node, err := cf.connect(ctx)
+	if err != nil {
+		return err
+	}
+	go node.Listen(ctx)
+	cctx, cancel := context.WithTimeout(ctx, cf.timeout)
+	defer cancel()
+
+	if notify {
+		return node.Notify(cctx, method, params)
+	}
+	res, err := node.Call(cctx, method, params)
+	if err != nil {
+		return describeError(err)
+	}
+	if len(res) == 0 {
+		res = json.RawMessage("null")
+	}
+	cf.print(res)
+	return nil
+}
+
+// describeError renders RPC errors with their data.
+func describeError(err error) error {
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		return err
+	}
+	msg := fmt.Sprintf("error %d: %s", rpcErr.Code, rpcErr.Message)
+	if len(rpcErr.Data) > 0 {
+		msg += "\n" + pretty(rpcErr.Data)
+	}
+	return errors.New(msg)
+}
+
+func runListen(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("listen", flag.ExitOnError)
+	var cf connFlags
+	cf.register(fs)
+	result := fs.String("result", "null", "JSON result returned for incoming calls")
+	if len(parseArgs(fs, args)) > 0 {
+		return fmt.Errorf("usage: nexio listen [flags]")
+	}
+	if !json.Valid([]byte(*result)) {
+		return fmt.Errorf("-result is not valid JSON")
+	}
+
+	node, err := cf.connect(ctx)
+	if err != nil {
+		return err
+	}
+	node.SetFallback(func(ctx context.Context, req rpc.Request) (any, error) {
+		kind := "call"
+		if req.IsNotification() {
+			kind = "notify"
+		}
+		cf.print(req.Params, kind+" "+req.Method)
+		return json.RawMessage(*result), nil
+	})
+	go node.Listen(ctx)
+
+	<-ctx.Done()
+	return nil
+}
+
+func runSubscribe(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("subscribe", flag.ExitOnError)
+	var cf connFlags
+	cf.register(fs)
+	patterns := parseArgs(fs, args)
+	if len(patterns) == 0 {
+		return fmt.Errorf("usage: nexio subscribe [flags] <pattern>...")
+	}
+
+	node, err := cf.connect(ctx)
+	if err != nil {
+		return err
+	}
+	client := pubsub.NewClient(node)
+	go node.Listen(ctx)
+	for _, pattern := range patterns {
+		err := client.Subscribe(ctx, pattern, func(ctx context.Context, topic string, payload json.RawMessage) {
+			cf.print(payload, topic)
+		})
+		if err != nil {
+			return fmt.Errorf("subscribe %s: %w", pattern, describeError(err))
+		}
+	}
+	fmt.Fprintf(os.Stderr, "subscribed to %s\n", strings.Join(patterns, ", "))
+
+	<-ctx.Done()
+	return nil
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+	"github.com/georghagn/nexio/node/transport/middleware"
+)
+
+// connFlags are shared by all commands that talk to one peer.
+type connFlags struct {
+	url     string
+	listen  string
+	timeout time.Duration
+	raw     bool
+	frames  bool
+	verbose bool
+}
+
+func (c *connFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&c.url, "url", "ws://localhost:8080/ws", "peer to dial")
+	fs.StringVar(&c.listen, "listen", "", "wait for a peer on this address (e.g. :8080) instead of dialing")
+	fs.DurationVar(&c.timeout, "timeout", 10*time.Second, "timeout for connecting and for each call")
+	fs.BoolVar(&c.raw, "raw", false, "print results as received")
+	fs.BoolVar(&c.frames, "frames", false, "dump every frame to stderr")
+	fs.BoolVar(&c.verbose, "v", false, "log connection events to stderr")
+}
+
+// connect returns a Node connected to the peer. It is not listening
+// yet: callers register their handlers first, then start node.Listen,
+// so that no request arrives before its handler.
+func (c *connFlags) connect(ctx context.Context) (*rpc.Node, error) {
+	logger := transport.LogSink(&transport.SilentLogger{})
+	if c.verbose {
+		logger = &stderrLog{}
+	}
+	provider := transport.NewWSProvider(logger)
+	provider.ReadLimit = 16 << 20
+
+	cctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	var conn transport.Connection
+	if c.listen != "" {
+		found := make(chan transport.Connection, 1)
+		go func() {
+			if err := provider.Listen(ctx, c.listen, found); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintf(os.Stderr, "nexio: listen: %v\n", err)
+			}
+		}()
+		fmt.Fprintf(os.Stderr, "waiting for a peer on %s ...\n", c.listen)
+		select {
+		case conn = <-found:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else {
+		var err error
+		if conn, err = provider.Dial(cctx, c.url); err != nil {
+			return nil, err
+		}
+	}
+
+	if c.frames {
+		conn = middleware.NewLogging(conn, &stderrLog{}, 1<<20)
+	}
+
+	var node *rpc.Node
+	if c.listen != "" {
+		node = rpc.NewNode(conn, nil, "", logger)
+	} else {
+		node = rpc.NewNode(conn, provider, c.url, logger)
+	}
+	return node, nil
+}
+
+// outMu keeps output of concurrent handlers apart.
+var outMu sync.Mutex
+
+// print writes lines and then a result or event payload to stdout.
+func (c *connFlags) print(data json.RawMessage, lines ...string) {
+	outMu.Lock()
+	defer outMu.Unlock()
+	for _, l := range lines {
+		fmt.Println(l)
+	}
+	if len(data) == 0 {
+		return
+	}
+	if c.raw {
+		fmt.Println(string(data))
+		return
+	}
+	fmt.Println(pretty(data))
+}
+
+func pretty(data json.RawMessage) string {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	out, _ := json.MarshalIndent(v, "", "  ")
+	return string(out)
+}
+
+// parseParams builds params from an argument ("-" = stdin) and -p pairs.
+func parseParams(arg string, pairs []string, stdin io.Reader) (json.RawMessage, error) {
+	if arg != "" && len(pairs) > 0 {
+		return nil, errors.New("use either a params document or -p, not both")
+	}
+	if len(pairs) > 0 {
+		obj := make(map[string]json.RawMessage, len(pairs))
+		for _, p := range pairs {
+			k, v, ok := strings.Cut(p, "=")
+			if !ok || k == "" {
+				return nil, fmt.Errorf("-p %q: want key=value", p)
+			}
+			// Values that are valid JSON keep their type, anything else is a string.
+			if !json.Valid([]byte(v)) {
+				quoted, _ := json.Marshal(v)
+				v = string(quoted)
+			}
+			obj[k] = json.RawMessage(v)
+		}
+		return json.Marshal(obj)
+	}
+
+	if arg == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, err
+		}
+		arg = strings.TrimSpace(string(data))
+	}
+	if arg == "" {
+		return nil, nil
+	}
+	if !json.Valid([]byte(arg)) {
+		return nil, fmt.Errorf("params are not valid JSON: %s", arg)
+	}
+	return json.RawMessage(arg), nil
+}
+
+// parseArgs parses flags that may also follow the positional arguments
+// ("nexio call method -p a=1") and returns the positional ones.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		if fs.NArg() == 0 {
+			return positional
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// multiFlag collects repeated flags.
+type multiFlag []string
+
+func (m *multiFlag) String() string     { return strings.Join(*m, ",") }
+func (m *multiFlag) Set(v string) error { *m = append(*m, v); return nil }
+
+// stderrLog is a minimal LogSink for -v and -frames.
+type stderrLog struct {
+	fields string
+}
+
+func (l *stderrLog) print(level, msg string) {
+	fmt.Fprintf(os.Stderr, "%s %-5s %s%s\n", time.Now().Format("15:04:05.000"), level, msg, l.fields)
+}
+
+func (l *stderrLog) Debug(msg string) { l.print("DEBUG", msg) }
+func (l *stderrLog) Info(msg string)  { l.print("INFO", msg) }
+func (l *stderrLog) Warn(msg string)  { l.print("WARN", msg) }
+func (l *stderrLog) Error(msg string) { l.print("ERROR", msg) }
+func (l *stderrLog) With(key string, value any) transport.LogSink {
+	return &stderrLog{fields: fmt.Sprintf("%s %s=%v", l.fields, key, value)}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+// nexio is a command line JSON-RPC client for any rpc.Node.
+//
+// Usage:
+//
+//	nexio call      [flags] <method> [params]   call a method and print the result
+//	nexio notify    [flags] <method> [params]   send a notification
+//	nexio listen    [flags]                     print incoming calls and notifications
+//	nexio subscribe [flags] <pattern>...        subscribe to pub/sub topics and print events
+//	nexio repl      [flags]                     interactive session
+//	nexio bench     [flags]                     load test a method
+//
+// Every command either dials a peer (-url ws://host:8080/ws, the default)
+// or waits for one peer to connect (-listen :8080).
+//
+// Params are a JSON document, "-" for stdin, or built from -p flags:
+//
+//	nexio call payment.process '{"order":"42"}'
+//	echo '[1,2]' | nexio call sum -
+//	nexio call payment.process -p order=42 -p amount=99.5
+//
+// Results are pretty-printed; -raw prints them as received and -frames
+// dumps every frame to stderr.
+//
+// bench drives -c concurrent Calls over -conns connections, optionally
+// paced to -rate calls/s, and reports throughput, latency percentiles,
+// errors and reconnects. It has a built-in echo server, so it can run on
+// localhost alone:
+//
+//	nexio bench -local -conns 4 -c 64 -size 1024 -d 10s
+//	nexio bench -serve :8080                        # echo server only
+//	nexio bench -url ws://payment:8080/ws -method payment.quote -rate 500
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch cmd, args := os.Args[1], os.Args[2:]; cmd {
+	case "call":
+		err = runCall(ctx, args, false)
+	case "notify":
+		err = runCall(ctx, args, true)
+	case "listen":
+		err = runListen(ctx, args)
+	case "subscribe":
+		err = runSubscribe(ctx, args)
+	case "repl":
+		err = runREPL(ctx, args)
+	case "bench":
+		err = runBench(ctx, args)
+	case "help", "-h", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "nexio: unknown command %q\n", cmd)
+		usage()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "nexio: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprint(os.Stderr, `usage: nexio <command> [flags] [args]
+
+commands:
+  call <method> [params]     call a method and print the result
+  notify <method> [params]   send a notification
+  listen                     print incoming calls and notifications
+  subscribe <pattern>...     subscribe to pub/sub topics and print events
+  repl                       interactive session with method completion
+  bench                      load test a method (or run an echo server)
+
+Run "nexio <command> -h" for the flags of a command.
+`)
+	os.Exit(2)
+}
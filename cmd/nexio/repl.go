@@ -0,0 +1,183 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+const replHelp = `  <method> [params]         call a method (exact name only)
+  notify <method> [params]  send a notification
+  <prefix>?                 list matching methods
+  .methods                  list methods with their params schema
+  .raw                      toggle raw output
+  .quit                     leave (also Ctrl-D)`
+
+func runREPL(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ExitOnError)
+	var cf connFlags
+	cf.register(fs)
+	if len(parseArgs(fs, args)) > 0 {
+		return fmt.Errorf("usage: nexio repl [flags]")
+	}
+
+	node, err := cf.connect(ctx)
+	if err != nil {
+		return err
+	}
+	node.SetFallback(func(ctx context.Context, req rpc.Request) (any, error) {
+		cf.print(req.Params, "\n<- "+req.Method)
+		return nil, nil
+	})
+	go node.Listen(ctx)
+
+	r := &repl{cf: &cf, node: node, out: os.Stdout}
+	r.discover(ctx)
+	return r.run(ctx, os.Stdin)
+}
+
+type repl struct {
+	cf      *connFlags
+	node    *rpc.Node
+	out     io.Writer
+	methods []rpc.MethodInfo // from rpc.discover, may be empty
+}
+
+// discover loads the method list for completion. Peers without
+// introspection simply get no completion.
+func (r *repl) discover(ctx context.Context) {
+	cctx, cancel := context.WithTimeout(ctx, r.cf.timeout)
+	defer cancel()
+	res, err := r.node.Call(cctx, rpc.MethodDiscover, nil)
+	if err != nil {
+		fmt.Fprintf(r.out, "(no introspection: %v)\n", err)
+		return
+	}
+	if err := json.Unmarshal(res, &r.methods); err != nil {
+		fmt.Fprintf(r.out, "(bad introspection answer: %v)\n", err)
+		return
+	}
+	fmt.Fprintf(r.out, "%d methods, type .methods or <prefix>? to list them\n", len(r.methods))
+}
+
+func (r *repl) run(ctx context.Context, in io.Reader) error {
+	sc := bufio.NewScanner(in)
+	sc.Buffer(make([]byte, 64*1024), 16<<20)
+	for {
+		fmt.Fprint(r.out, "> ")
+		if !sc.Scan() {
+			fmt.Fprintln(r.out)
+			return sc.Err()
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		if !r.exec(ctx, strings.TrimSpace(sc.Text())) {
+			return nil
+		}
+	}
+}
+
+// exec runs one line and reports whether to go on.
+func (r *repl) exec(ctx context.Context, line string) bool {
+	switch line {
+	case "":
+		return true
+	case ".quit", ".exit":
+		return false
+	case ".help", "help":
+		fmt.Fprintln(r.out, replHelp)
+		return true
+	case ".raw":
+		r.cf.raw = !r.cf.raw
+		fmt.Fprintf(r.out, "raw output %v\n", r.cf.raw)
+		return true
+	case ".methods":
+		for _, m := range r.methods {
+			fmt.Fprintf(r.out, "  %s\n", m.Name)
+			if m.Params != nil {
+				schema, _ := json.Marshal(m.Params)
+				fmt.Fprintf(r.out, "      params: %s\n", schema)
+			}
+		}
+		return true
+	}
+
+	if strings.HasSuffix(line, "?") && !strings.ContainsAny(line, " \t") {
+		for _, name := range r.complete(strings.TrimSuffix(line, "?")) {
+			fmt.Fprintf(r.out, "  %s\n", name)
+		}
+		return true
+	}
+
+	notify := false
+	if rest, ok := strings.CutPrefix(line, "notify "); ok {
+		notify, line = true, strings.TrimSpace(rest)
+	}
+	method, paramText, _ := strings.Cut(line, " ")
+	method, ok := r.resolve(method)
+	if !ok {
+		return true
+	}
+	params, err := parseParams(strings.TrimSpace(paramText), nil, nil)
+	if err != nil {
+		fmt.Fprintln(r.out, err)
+		return true
+	}
+
+	cctx, cancel := context.WithTimeout(ctx, r.cf.timeout)
+	defer cancel()
+	if notify {
+		if err := r.node.Notify(cctx, method, params); err != nil {
+			fmt.Fprintln(r.out, err)
+		}
+		return true
+	}
+	res, err := r.node.Call(cctx, method, params)
+	if err != nil {
+		fmt.Fprintln(r.out, describeError(err))
+		return true
+	}
+	if len(res) == 0 {
+		res = json.RawMessage("null")
+	}
+	r.cf.print(res)
+	return true
+}
+
+// complete returns the known methods starting with prefix.
+func (r *repl) complete(prefix string) []string {
+	var out []string
+	for _, m := range r.methods {
+		if strings.HasPrefix(m.Name, prefix) {
+			out = append(out, m.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// resolve accepts only exact names: a prefix of known methods lists
+// them instead of calling one, so a short or mistyped name never runs a
+// different method. Unknown names are passed on as is, the peer may
+// still know them.
+func (r *repl) resolve(name string) (string, bool) {
+	candidates := r.complete(name)
+	if len(candidates) == 0 || slices.Contains(candidates, name) {
+		return name, true
+	}
+	fmt.Fprintf(r.out, "unknown method %s, did you mean: %s\n", name, strings.Join(candidates, ", "))
+	return "", false
+}
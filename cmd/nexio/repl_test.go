@@ -0,0 +1,57 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestREPL_CallsExactNamesOnly(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	server := rpc.NewNode(serverConn, nil, "", nil)
+	client := rpc.NewNode(clientConn, nil, "", nil)
+
+	var mu sync.Mutex
+	var called []string
+	for _, name := range []string{"payment.refund", "payment.refundAll", "payment.status"} {
+		server.Register(name, func(ctx context.Context, p json.RawMessage) (any, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			called = append(called, name)
+			return "done", nil
+		})
+	}
+	server.EnableIntrospection()
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+
+	var out bytes.Buffer
+	r := &repl{cf: &connFlags{timeout: time.Second, raw: true}, node: client, out: &out}
+	r.discover(ctx)
+
+	for _, line := range []string{"payment.stat", "payment.refundA", "payment.refund", "payment.refundAll"} {
+		r.exec(ctx, line)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(called, " ") != "payment.refund payment.refundAll" {
+		t.Errorf("Expected only exact names to be called, got %v", called)
+	}
+	if !strings.Contains(out.String(), "did you mean: payment.status") {
+		t.Errorf("Expected candidates to be listed, got:\n%s", out.String())
+	}
+}
@@ -76,7 +76,7 @@ func main() {
 func handleNewPeer(
 	ctx context.Context,
 	conn transport.Connection,
-	provider *transport.WSProvider,
+	provider transport.Dialer,
 	dialAddr string,
 	logger transport.LogSink) {
 
@@ -0,0 +1,54 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+// Member is one connected Node of a Client or Pool.
+type Member struct {
+	Addr string
+	Node *rpc.Node
+}
+
+// Balancer picks the member for the next Call.
+// It only sees connected members and is never called with an empty slice.
+type Balancer interface {
+	Pick(members []*Member) *Member
+}
+
+// RoundRobin cycles through the members.
+type RoundRobin struct {
+	next atomic.Uint64
+}
+
+func (r *RoundRobin) Pick(members []*Member) *Member {
+	n := r.next.Add(1) - 1
+	return members[n%uint64(len(members))]
+}
+
+// Random picks a random member.
+type Random struct{}
+
+func (Random) Pick(members []*Member) *Member {
+	return members[rand.Intn(len(members))]
+}
+
+// LeastPending picks the member with the fewest outstanding Calls.
+type LeastPending struct{}
+
+func (LeastPending) Pick(members []*Member) *Member {
+	best := members[0]
+	bestN := best.Node.Pending()
+	for _, m := range members[1:] {
+		if n := m.Node.Pending(); n < bestN {
+			best, bestN = m, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,205 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type member struct {
+	Member
+	cancel context.CancelFunc
+}
+
+// Client spreads Calls over one Node per endpoint returned by a Resolver.
+// Every Node dials and reconnects on its own; Calls that fail because a
+// connection died are retried on another endpoint.
+//
+// A retried Call may have reached the dead peer already, so methods
+// should be idempotent, or the caller sets a key with
+// rpc.WithIdempotencyKey that the members deduplicate (rpc.Idempotency).
+type Client struct {
+	resolver Resolver
+	provider transport.Dialer
+
+	Balancer        Balancer
+	RefreshInterval time.Duration
+	Setup           func(node *rpc.Node) // e.g. register handlers on every member
+	Log             transport.LogSink
+
+	mu      sync.RWMutex
+	members map[string]*member
+
+	readyOnce sync.Once
+	ready     chan struct{}
+}
+
+func NewClient(resolver Resolver, provider transport.Dialer, logger transport.LogSink) *Client {
+	c := &Client{
+		resolver:        resolver,
+		provider:        provider,
+		Balancer:        &RoundRobin{},
+		RefreshInterval: 30 * time.Second,
+		Log:             &transport.SilentLogger{},
+		members:         make(map[string]*member),
+		ready:           make(chan struct{}),
+	}
+	if logger != nil {
+		c.Log = logger
+	}
+	return c
+}
+
+// Start resolves the endpoints once and then keeps the member set in
+// sync until ctx ends. All member Nodes stop with ctx.
+func (c *Client) Start(ctx context.Context) error {
+	if err := c.refresh(ctx); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(c.RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := c.refresh(ctx); err != nil {
+					c.Log.With("error", err).Warn("Resolve failed, keeping current endpoints")
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+// WaitReady blocks until at least one member is connected.
+func (c *Client) WaitReady(ctx context.Context) error {
+	select {
+	case <-c.ready:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *Client) refresh(ctx context.Context) error {
+	addrs, err := c.resolver.Resolve(ctx)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]bool, len(addrs))
+	for _, a := range addrs {
+		want[a] = true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for addr, m := range c.members {
+		if !want[addr] {
+			c.Log.With("addr", addr).Info("Endpoint removed")
+			m.cancel()
+			delete(c.members, addr)
+		}
+	}
+	for addr := range want {
+		if _, ok := c.members[addr]; !ok {
+			c.Log.With("addr", addr).Info("Endpoint added")
+			c.members[addr] = c.startMember(ctx, addr)
+		}
+	}
+	return nil
+}
+
+func (c *Client) startMember(ctx context.Context, addr string) *member {
+	mctx, cancel := context.WithCancel(ctx)
+	node := rpc.NewNode(nil, c.provider, addr, c.Log.With("endpoint", addr))
+	node.OnConnect(func(ctx context.Context) {
+		c.readyOnce.Do(func() { close(c.ready) })
+	})
+	if c.Setup != nil {
+		c.Setup(node)
+	}
+	go node.Listen(mctx)
+	return &member{Member: Member{Addr: addr, Node: node}, cancel: cancel}
+}
+
+// Members returns the currently connected members.
+func (c *Client) Members() []*Member {
+	return c.connected(nil)
+}
+
+func (c *Client) connected(skip map[string]bool) []*Member {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]*Member, 0, len(c.members))
+	for addr, m := range c.members {
+		if !skip[addr] && m.Node.Connected() {
+			out = append(out, &m.Member)
+		}
+	}
+	return out
+}
+
+// Call sends the request to a member picked by the Balancer and fails
+// over to the next member on connection errors.
+func (c *Client) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	tried := make(map[string]bool)
+	var lastErr error
+	for {
+		m := c.pick(tried)
+		if m == nil {
+			return nil, noEndpoint(lastErr)
+		}
+		res, err := m.Node.Call(ctx, method, params)
+		if !rpc.IsConnectionError(err) {
+			return res, err
+		}
+		c.Log.With("addr", m.Addr).With("error", err).Warn("Call failed, trying next endpoint")
+		lastErr = err
+	}
+}
+
+// Notify sends a notification to one member, failing over like Call.
+func (c *Client) Notify(ctx context.Context, method string, params any) error {
+	tried := make(map[string]bool)
+	var lastErr error
+	for {
+		m := c.pick(tried)
+		if m == nil {
+			return noEndpoint(lastErr)
+		}
+		err := m.Node.Notify(ctx, method, params)
+		if !rpc.IsConnectionError(err) {
+			return err
+		}
+		lastErr = err
+	}
+}
+
+// pick returns a connected member that was not tried yet (nil if none).
+func (c *Client) pick(tried map[string]bool) *Member {
+	candidates := c.connected(tried)
+	if len(candidates) == 0 {
+		return nil
+	}
+	m := c.Balancer.Pick(candidates)
+	tried[m.Addr] = true
+	return m
+}
+
+func noEndpoint(lastErr error) error {
+	if lastErr != nil {
+		return lastErr
+	}
+	return rpc.NewRPCError(rpc.ErrConnectionLostError, "no endpoint available")
+}
@@ -0,0 +1,200 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestRoundRobin(t *testing.T) {
+	members := []*Member{{Addr: "a"}, {Addr: "b"}, {Addr: "c"}}
+	rr := &RoundRobin{}
+	var got string
+	for i := 0; i < 4; i++ {
+		got += rr.Pick(members).Addr
+	}
+	if got != "abca" {
+		t.Errorf("Expected abca, got %s", got)
+	}
+}
+
+func TestLeastPending(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The peer of busy never answers, so its Call stays pending.
+	busyConn, _ := transport.NewMemPair()
+	busy := rpc.NewNode(busyConn, nil, "", nil)
+	idle := rpc.NewNode(nil, nil, "", nil)
+	go busy.Call(ctx, "slow", nil)
+	for busy.Pending() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	m := LeastPending{}.Pick([]*Member{{Addr: "busy", Node: busy}, {Addr: "idle", Node: idle}})
+	if m.Addr != "idle" {
+		t.Errorf("Expected idle member, got %s", m.Addr)
+	}
+}
+
+func TestFileResolver(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "endpoints")
+	os.WriteFile(path, []byte("# payment\nws://a:8080/ws\n\nws://b:8080/ws\n"), 0o644)
+
+	r := &FileResolver{Path: path}
+	addrs, err := r.Resolve(context.Background())
+	if err != nil || len(addrs) != 2 || addrs[1] != "ws://b:8080/ws" {
+		t.Fatalf("Unexpected endpoints %v (%v)", addrs, err)
+	}
+
+	// A changed file is picked up.
+	later := time.Now().Add(time.Second)
+	os.WriteFile(path, []byte("ws://c:8080/ws\n"), 0o644)
+	os.Chtimes(path, later, later)
+	addrs, _ = r.Resolve(context.Background())
+	if len(addrs) != 1 || addrs[0] != "ws://c:8080/ws" {
+		t.Errorf("Expected reloaded endpoints, got %v", addrs)
+	}
+}
+
+// serveWhoami starts a WebSocket endpoint answering "whoami" with name.
+// Cancelling the returned ctx shuts it down and drops its connections.
+func serveWhoami(t *testing.T, parent context.Context, name string) (string, context.CancelFunc) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(parent)
+	found := make(chan transport.Connection)
+	go transport.NewWSProvider(nil).Listen(ctx, addr, found)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := rpc.NewNode(conn, nil, "", nil)
+				node.Register("whoami", func(ctx context.Context, p json.RawMessage) (any, error) {
+					return name, nil
+				})
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return fmt.Sprintf("ws://%s/ws", addr), cancel
+}
+
+func TestClient_Failover(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	a, stopA := serveWhoami(t, ctx, "a")
+	b, stopB := serveWhoami(t, ctx, "b")
+
+	c := NewClient(StaticResolver{a, b}, transport.NewWSProvider(nil), nil)
+	if err := c.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for len(c.Members()) < 2 {
+		if time.Now().After(deadline) {
+			t.Fatal("members did not connect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Calls routed to the dead endpoint fail over to the live one.
+	stopA()
+	for i := 0; i < 4; i++ {
+		res, err := c.Call(ctx, "whoami", nil)
+		if err != nil || string(res) != `"b"` {
+			t.Fatalf("call %d: %s %v", i, res, err)
+		}
+	}
+
+	stopB()
+	if _, err := c.Call(ctx, "whoami", nil); !rpc.IsConnectionError(err) {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPool_ReplacesDeadMembers(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := transport.NewMemNetwork()
+	found := make(chan transport.Connection)
+	go net.Provider("server").Listen(ctx, "svc", found)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := rpc.NewNode(conn, nil, "", nil)
+				node.Register("ping", func(ctx context.Context, p json.RawMessage) (any, error) {
+					return "pong", nil
+				})
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	var created atomic.Int32
+	pool := NewPool("svc", 2, net.Provider("client"), nil)
+	pool.DeadAfter = 20 * time.Millisecond
+	pool.Setup = func(node *rpc.Node) {
+		created.Add(1)
+		node.SetReconnectBackoff(time.Millisecond, 5*time.Millisecond)
+	}
+	pool.Start(ctx)
+
+	waitFor(t, "members", func() bool { return len(pool.Members()) == 2 })
+	if res, err := pool.Call(ctx, "ping", nil); err != nil || string(res) != `"pong"` {
+		t.Fatalf("Unexpected result %s (%v)", res, err)
+	}
+
+	// Members that cannot reconnect in time are replaced by fresh Nodes.
+	net.Partition("client", "server")
+	waitFor(t, "replacement", func() bool { return created.Load() >= 4 })
+	if _, err := pool.Call(ctx, "ping", nil); !rpc.IsConnectionError(err) {
+		t.Fatalf("Expected connection error during partition, got %v", err)
+	}
+
+	net.Heal("client", "server")
+	waitFor(t, "members after heal", func() bool { return len(pool.Members()) == 2 })
+	if res, err := pool.Call(ctx, "ping", nil); err != nil || string(res) != `"pong"` {
+		t.Fatalf("Unexpected result after heal %s (%v)", res, err)
+	}
+
+	// Connected members are left alone.
+	n := created.Load()
+	time.Sleep(3 * pool.DeadAfter)
+	if created.Load() != n {
+		t.Errorf("Expected no replacements while connected, got %d new", created.Load()-n)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package cluster spreads Calls over several Nodes.
+
+A Resolver yields the endpoints of a service (StaticResolver, SRVResolver
+or FileResolver). The Client keeps one reconnecting Node per endpoint,
+picks a connected one per Call with a Balancer (RoundRobin, Random,
+LeastPending) and fails over to another endpoint when a connection dies.
+
+	c := cluster.NewClient(&cluster.FileResolver{Path: "payment.endpoints"}, provider, logger)
+	c.Balancer = cluster.LeastPending{}
+	c.Start(ctx)
+	c.WaitReady(ctx)
+
+	res, err := c.Call(ctx, "payment.process", orderID)
+
+A Pool keeps several connections to a single endpoint and behaves like
+one logical peer; handlers registered on the Pool exist on every member.
+
+	pool := cluster.NewPool("ws://payment:8080/ws", 4, provider, logger)
+	pool.Register("order.update", onUpdate)
+	pool.Start(ctx)
+*/
+package cluster
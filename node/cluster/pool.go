@@ -0,0 +1,215 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Pool keeps Size connections to the same address and balances Calls
+// across them. WSConnection serializes writes, so several connections
+// avoid head-of-line blocking for large payloads.
+//
+// To the application the Pool is one logical peer: handlers registered
+// on the Pool are installed on every member, including members that
+// replace dead ones later.
+//
+// A member that stays disconnected for longer than DeadAfter is dead:
+// it is stopped and a fresh Node takes its slot.
+type Pool struct {
+	addr     string
+	provider transport.Dialer
+	size     int
+
+	Balancer  Balancer
+	DeadAfter time.Duration        // 0 = DefaultDeadAfter
+	Setup     func(node *rpc.Node) // runs on every new member
+	Log       transport.LogSink
+
+	mu       sync.RWMutex
+	members  []*Member
+	handlers map[string]rpc.HandlerFunc
+}
+
+// DefaultDeadAfter is the default Pool.DeadAfter.
+const DefaultDeadAfter = 30 * time.Second
+
+func NewPool(addr string, size int, provider transport.Dialer, logger transport.LogSink) *Pool {
+	if size < 1 {
+		size = 1
+	}
+	p := &Pool{
+		addr:     addr,
+		provider: provider,
+		size:     size,
+		Balancer: LeastPending{},
+		Log:      &transport.SilentLogger{},
+		handlers: make(map[string]rpc.HandlerFunc),
+	}
+	if logger != nil {
+		p.Log = logger
+	}
+	return p
+}
+
+// Register installs h on all current and future members.
+func (p *Pool) Register(method string, h rpc.HandlerFunc) {
+	p.mu.Lock()
+	p.handlers[method] = h
+	members := append([]*Member(nil), p.members...)
+	p.mu.Unlock()
+
+	for _, m := range members {
+		m.Node.Register(method, h)
+	}
+}
+
+// Start opens the connections. Disconnected members reconnect on their
+// own (Node backoff) and are skipped meanwhile. Members that are dead
+// (see DeadAfter) or whose Node gives up are replaced until ctx ends.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.size; i++ {
+		go p.supervise(ctx, i)
+	}
+}
+
+func (p *Pool) supervise(ctx context.Context, slot int) {
+	for ctx.Err() == nil {
+		mctx, kill := context.WithCancel(ctx)
+		node := p.newMember(slot)
+		go p.watch(mctx, kill, node, slot)
+		err := node.Listen(mctx)
+		kill()
+		p.remove(node)
+		if ctx.Err() != nil {
+			return
+		}
+		if mctx.Err() != nil {
+			continue // killed by watch, replace right away
+		}
+		p.Log.With("slot", slot).With("error", err).Warn("Pool member stopped, replacing it")
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+// watch kills the member once it was disconnected for DeadAfter.
+func (p *Pool) watch(ctx context.Context, kill context.CancelFunc, node *rpc.Node, slot int) {
+	deadAfter := p.DeadAfter
+	if deadAfter <= 0 {
+		deadAfter = DefaultDeadAfter
+	}
+	ticker := time.NewTicker(max(deadAfter/4, time.Millisecond))
+	defer ticker.Stop()
+
+	since := time.Now() // a new member starts disconnected
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			switch {
+			case node.Connected():
+				since = time.Time{}
+			case since.IsZero():
+				since = now
+			case now.Sub(since) >= deadAfter:
+				p.Log.With("slot", slot).With("down", now.Sub(since)).Warn("Pool member dead, replacing it")
+				kill()
+				return
+			}
+		}
+	}
+}
+
+func (p *Pool) newMember(slot int) *rpc.Node {
+	node := rpc.NewNode(nil, p.provider, p.addr, p.Log.With("slot", slot))
+	if p.Setup != nil {
+		p.Setup(node)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for method, h := range p.handlers {
+		node.Register(method, h)
+	}
+	p.members = append(p.members, &Member{Addr: fmt.Sprintf("%s#%d", p.addr, slot), Node: node})
+	return node
+}
+
+func (p *Pool) remove(node *rpc.Node) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for i, m := range p.members {
+		if m.Node == node {
+			p.members = append(p.members[:i], p.members[i+1:]...)
+			return
+		}
+	}
+}
+
+// Members returns the currently connected members.
+func (p *Pool) Members() []*Member {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	out := make([]*Member, 0, len(p.members))
+	for _, m := range p.members {
+		if m.Node.Connected() {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+// Call sends the request over a member picked by the Balancer and fails
+// over to another member on connection errors.
+func (p *Pool) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	var lastErr error
+	for _, m := range p.order() {
+		res, err := m.Node.Call(ctx, method, params)
+		if !rpc.IsConnectionError(err) {
+			return res, err
+		}
+		lastErr = err
+	}
+	return nil, noEndpoint(lastErr)
+}
+
+// Notify sends a notification over one member.
+func (p *Pool) Notify(ctx context.Context, method string, params any) error {
+	var lastErr error
+	for _, m := range p.order() {
+		err := m.Node.Notify(ctx, method, params)
+		if !rpc.IsConnectionError(err) {
+			return err
+		}
+		lastErr = err
+	}
+	return noEndpoint(lastErr)
+}
+
+// order returns the connected members, the Balancer's pick first.
+func (p *Pool) order() []*Member {
+	members := p.Members()
+	if len(members) == 0 {
+		return nil
+	}
+	first := p.Balancer.Pick(members)
+	out := []*Member{first}
+	for _, m := range members {
+		if m != first {
+			out = append(out, m)
+		}
+	}
+	return out
+}
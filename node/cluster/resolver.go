@@ -0,0 +1,105 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package cluster
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Resolver returns the current set of endpoints (ws:// URLs).
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// StaticResolver always returns the same endpoints.
+type StaticResolver []string
+
+func (s StaticResolver) Resolve(ctx context.Context) ([]string, error) {
+	return append([]string(nil), s...), nil
+}
+
+// SRVResolver looks up endpoints via DNS SRV records,
+// e.g. _nexio._tcp.payment.example.com.
+type SRVResolver struct {
+	Service string // "nexio"
+	Proto   string // "tcp"
+	Name    string // "payment.example.com"
+	Scheme  string // default "ws"
+	Path    string // default "/ws"
+}
+
+func (r *SRVResolver) Resolve(ctx context.Context) ([]string, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, r.Service, r.Proto, r.Name)
+	if err != nil {
+		return nil, err
+	}
+	scheme, path := r.Scheme, r.Path
+	if scheme == "" {
+		scheme = "ws"
+	}
+	if path == "" {
+		path = "/ws"
+	}
+
+	addrs := make([]string, 0, len(records))
+	for _, rec := range records {
+		host := strings.TrimSuffix(rec.Target, ".")
+		addrs = append(addrs, fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(host, fmt.Sprint(rec.Port)), path))
+	}
+	return addrs, nil
+}
+
+// FileResolver reads one endpoint per line from a file. Empty lines and
+// lines starting with '#' are ignored. The file is only re-read when its
+// modification time changes, so it can be polled cheaply.
+type FileResolver struct {
+	Path string
+
+	mu      sync.Mutex
+	modTime time.Time
+	cached  []string
+}
+
+func (r *FileResolver) Resolve(ctx context.Context) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	info, err := os.Stat(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	if r.cached != nil && info.ModTime().Equal(r.modTime) {
+		return append([]string(nil), r.cached...), nil
+	}
+
+	f, err := os.Open(r.Path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	addrs := []string{}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		addrs = append(addrs, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+
+	r.cached = addrs
+	r.modTime = info.ModTime()
+	return append([]string(nil), addrs...), nil
+}
@@ -0,0 +1,19 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package metrics provides a small in-memory implementation of transport.Metrics.
+
+The Registry collects counters, gauges and histograms reported by rpc.Node,
+transport.WSProvider and transport.WSConnection and renders them in the
+Prometheus text exposition format. No client library is required.
+
+Example:
+
+	reg := metrics.NewRegistry()
+	provider.Metrics = reg
+	node.SetMetrics(reg)
+
+	http.Handle("/metrics", reg)
+*/
+package metrics
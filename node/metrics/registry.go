@@ -0,0 +1,235 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the histogram upper bounds in seconds.
+var DefaultBuckets = []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+type kind int
+
+const (
+	kindCounter kind = iota
+	kindGauge
+	kindHistogram
+)
+
+// series is one metric with a fixed label set.
+type series struct {
+	labels string // rendered label set, e.g. {method="ping",code="ok"}
+	value  float64
+
+	// histogram only
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+type family struct {
+	kind    kind
+	buckets []float64
+	series  map[string]*series
+}
+
+// Registry is an in-memory implementation of transport.Metrics.
+// It renders the Prometheus text exposition format and can be
+// mounted directly as an http.Handler (e.g. on "/metrics").
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+	buckets  map[string][]float64
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		families: make(map[string]*family),
+		buckets:  make(map[string][]float64),
+	}
+}
+
+// SetBuckets overrides the histogram buckets for a metric name.
+// It must be called before the first observation of that metric.
+func (r *Registry) SetBuckets(name string, buckets []float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	b := append([]float64(nil), buckets...)
+	sort.Float64s(b)
+	r.buckets[name] = b
+}
+
+func (r *Registry) Counter(name string, delta float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.get(name, kindCounter, labels).value += delta
+}
+
+func (r *Registry) Gauge(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.get(name, kindGauge, labels).value = value
+}
+
+func (r *Registry) Observe(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	s := r.get(name, kindHistogram, labels)
+	f := r.families[name]
+	for i, b := range f.buckets {
+		if value <= b {
+			s.counts[i]++
+		}
+	}
+	s.sum += value
+	s.count++
+}
+
+// Value returns the current value of a counter or gauge (0 if unknown).
+// For histograms it returns the number of observations.
+func (r *Registry) Value(name string, labels ...string) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f, ok := r.families[name]
+	if !ok {
+		return 0
+	}
+	s, ok := f.series[renderLabels(labels)]
+	if !ok {
+		return 0
+	}
+	if f.kind == kindHistogram {
+		return float64(s.count)
+	}
+	return s.value
+}
+
+// get returns the series and creates it on first use. Caller holds r.mu.
+func (r *Registry) get(name string, k kind, labels []string) *series {
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{kind: k, series: make(map[string]*series)}
+		if k == kindHistogram {
+			f.buckets = r.buckets[name]
+			if f.buckets == nil {
+				f.buckets = DefaultBuckets
+			}
+		}
+		r.families[name] = f
+	}
+
+	key := renderLabels(labels)
+	s, ok := f.series[key]
+	if !ok {
+		s = &series{labels: key}
+		if f.kind == kindHistogram {
+			s.counts = make([]uint64, len(f.buckets))
+		}
+		f.series[key] = s
+	}
+	return s
+}
+
+// WritePrometheus writes all metrics in the text exposition format.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bw := bufio.NewWriter(w)
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		f := r.families[name]
+		fmt.Fprintf(bw, "# TYPE %s %s\n", name, f.kind)
+
+		keys := make([]string, 0, len(f.series))
+		for k := range f.series {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s := f.series[k]
+			if f.kind != kindHistogram {
+				fmt.Fprintf(bw, "%s%s %s\n", name, s.labels, formatFloat(s.value))
+				continue
+			}
+			for i, b := range f.buckets {
+				fmt.Fprintf(bw, "%s_bucket%s %d\n", name, withLabel(s.labels, "le", formatFloat(b)), s.counts[i])
+			}
+			fmt.Fprintf(bw, "%s_bucket%s %d\n", name, withLabel(s.labels, "le", "+Inf"), s.count)
+			fmt.Fprintf(bw, "%s_sum%s %s\n", name, s.labels, formatFloat(s.sum))
+			fmt.Fprintf(bw, "%s_count%s %d\n", name, s.labels, s.count)
+		}
+	}
+	return bw.Flush()
+}
+
+// ServeHTTP makes the Registry usable as a scrape endpoint.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WritePrometheus(w)
+}
+
+func (k kind) String() string {
+	switch k {
+	case kindGauge:
+		return "gauge"
+	case kindHistogram:
+		return "histogram"
+	default:
+		return "counter"
+	}
+}
+
+// renderLabels turns key/value pairs into {k1="v1",k2="v2"}.
+// A trailing key without value is ignored.
+func renderLabels(labels []string) string {
+	if len(labels) < 2 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i := 0; i+1 < len(labels); i += 2 {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(labels[i])
+		sb.WriteString(`="`)
+		sb.WriteString(escape(labels[i+1]))
+		sb.WriteByte('"')
+	}
+	sb.WriteByte('}')
+	return sb.String()
+}
+
+func withLabel(rendered, key, value string) string {
+	l := key + `="` + value + `"`
+	if rendered == "" {
+		return "{" + l + "}"
+	}
+	return rendered[:len(rendered)-1] + "," + l + "}"
+}
+
+func escape(v string) string {
+	v = strings.ReplaceAll(v, `\`, `\\`)
+	v = strings.ReplaceAll(v, "\n", `\n`)
+	return strings.ReplaceAll(v, `"`, `\"`)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestRegistryExposition(t *testing.T) {
+	reg := NewRegistry()
+	reg.Counter("calls_total", 1, "method", "ping", "code", "ok")
+	reg.Counter("calls_total", 2, "method", "ping", "code", "ok")
+	reg.Gauge("pending", 3)
+	reg.SetBuckets("latency", []float64{0.1, 1})
+	reg.Observe("latency", 0.5, "method", "ping")
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	out := rec.Body.String()
+
+	for _, want := range []string{
+		"# TYPE calls_total counter",
+		`calls_total{method="ping",code="ok"} 3`,
+		"pending 3",
+		`latency_bucket{method="ping",le="0.1"} 0`,
+		`latency_bucket{method="ping",le="1"} 1`,
+		`latency_bucket{method="ping",le="+Inf"} 1`,
+		`latency_count{method="ping"} 1`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("Expected %q in output:\n%s", want, out)
+		}
+	}
+}
+
+func TestNodeReportsMetrics(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := NewRegistry()
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := rpc.NewNode(serverConn, nil, "", nil)
+	clientNode := rpc.NewNode(clientConn, nil, "", nil)
+	serverNode.SetMetrics(reg)
+	clientNode.SetMetrics(reg)
+
+	serverNode.Register("ping", func(ctx context.Context, p json.RawMessage) (any, error) {
+		return "pong", nil
+	})
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	if _, err := clientNode.Call(ctx, "ping", nil); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	clientNode.Call(ctx, "missing", nil)
+
+	if v := reg.Value(rpc.MetricCalls, "method", "ping", "code", rpc.CodeOK); v != 1 {
+		t.Errorf("Expected 1 successful call, got %v", v)
+	}
+	if v := reg.Value(rpc.MetricCalls, "method", "missing", "code", "-32601"); v != 1 {
+		t.Errorf("Expected 1 method-not-found call, got %v", v)
+	}
+	if v := reg.Value(rpc.MetricCallDuration, "method", "ping", "code", rpc.CodeOK); v != 1 {
+		t.Errorf("Expected 1 latency observation, got %v", v)
+	}
+
+	// Peer-chosen names do not become labels on the serving side.
+	clientNode.Call(ctx, "random.a", nil)
+	clientNode.Call(ctx, "random.b", nil)
+	if v := reg.Value(rpc.MetricRequests, "method", rpc.MethodUnknown, "code", "-32601"); v != 3 {
+		t.Errorf("Expected 3 unknown requests, got %v", v)
+	}
+	if v := reg.Value(rpc.MetricRequests, "method", "random.a", "code", "-32601"); v != 0 {
+		t.Errorf("Expected no series for an unknown method, got %v", v)
+	}
+
+	// Callbacks share one label.
+	serverNode.Register("job", func(ctx context.Context, p json.RawMessage) (any, error) {
+		cb, err := rpc.Bind[rpc.Callback](p)
+		if err != nil {
+			return nil, err
+		}
+		return cb.Call(ctx, nil)
+	})
+	for range 2 {
+		cb := clientNode.NewCallback(func(ctx context.Context, p json.RawMessage) (any, error) {
+			return true, nil
+		})
+		if _, err := clientNode.Call(ctx, "job", cb); err != nil {
+			t.Fatalf("job: %v", err)
+		}
+		clientNode.ReleaseCallback(cb)
+	}
+	if v := reg.Value(rpc.MetricRequests, "method", "rpc.callback.", "code", rpc.CodeOK); v != 2 {
+		t.Errorf("Expected 2 callback requests under one label, got %v", v)
+	}
+	if v := reg.Value(rpc.MetricCalls, "method", "rpc.callback.", "code", rpc.CodeOK); v != 2 {
+		t.Errorf("Expected 2 callback calls under one label, got %v", v)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package nexiotest provides fixtures for testing services built on rpc.Node.
+
+PeerPair wires two Nodes over memory and cleans up after the test:
+
+	p := nexiotest.NewPeerPair(t)
+	registerOrderHandlers(p.Server)
+
+	updates := nexiotest.Record(p.Client, "order.updated", nil)
+	res, err := p.Client.Call(p.Ctx, "order.create", order)
+	...
+	n := updates.ExpectNotifications(t, 1)
+	nexiotest.AssertJSON(t, n[0].Params, map[string]any{"id": "42"})
+
+MockPeer is a programmable fake remote for contract tests: expected
+methods with canned results, errors or delays, call ordering, and a dump
+of unexpected calls. Expectations can be loaded from JSON fixtures shared
+with other teams (see Fixture).
+
+	mock := nexiotest.NewMockPeer(t)
+	mock.LoadFixture("testdata/payment.json")
+	orders := NewOrderService(rpc.NewNode(mock.Conn, nil, "", nil))
+
+FakeClock drives reconnect backoff without waiting (see rpc.Node.SetClock),
+RecordingSink captures log output for assertions.
+*/
+package nexiotest
@@ -0,0 +1,141 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+// Timeout bounds every Expect helper.
+var Timeout = 2 * time.Second
+
+// Invocation is one recorded call or notification.
+type Invocation struct {
+	Method       string
+	Params       json.RawMessage
+	Notification bool
+}
+
+// Recorder registers a handler that records its invocations.
+type Recorder struct {
+	mu      sync.Mutex
+	calls   []Invocation
+	changed chan struct{}
+}
+
+// Record registers method on node. Calls are answered with result.
+func Record(node *rpc.Node, method string, result any) *Recorder {
+	r := &Recorder{changed: make(chan struct{})}
+	node.Register(method, func(ctx context.Context, p json.RawMessage) (any, error) {
+		inv := Invocation{Method: method, Params: p}
+		if req, ok := rpc.RequestFromContext(ctx); ok {
+			inv.Notification = req.IsNotification()
+		}
+		r.mu.Lock()
+		r.calls = append(r.calls, inv)
+		close(r.changed)
+		r.changed = make(chan struct{})
+		r.mu.Unlock()
+		return result, nil
+	})
+	return r
+}
+
+// Invocations returns everything recorded so far.
+func (r *Recorder) Invocations() []Invocation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Invocation(nil), r.calls...)
+}
+
+// ExpectCalls waits until n calls (requests with id) arrived and returns them.
+func (r *Recorder) ExpectCalls(t testing.TB, n int) []Invocation {
+	t.Helper()
+	return r.expect(t, n, false)
+}
+
+// ExpectNotifications waits until n notifications arrived and returns them.
+func (r *Recorder) ExpectNotifications(t testing.TB, n int) []Invocation {
+	t.Helper()
+	return r.expect(t, n, true)
+}
+
+// ExpectNone fails if anything arrives within d.
+func (r *Recorder) ExpectNone(t testing.TB, d time.Duration) {
+	t.Helper()
+	time.Sleep(d)
+	if got := r.Invocations(); len(got) > 0 {
+		t.Fatalf("expected no invocations, got %d (first: %s %s)", len(got), got[0].Method, got[0].Params)
+	}
+}
+
+func (r *Recorder) expect(t testing.TB, n int, notification bool) []Invocation {
+	t.Helper()
+	deadline := time.NewTimer(Timeout)
+	defer deadline.Stop()
+	for {
+		r.mu.Lock()
+		var got []Invocation
+		for _, c := range r.calls {
+			if c.Notification == notification {
+				got = append(got, c)
+			}
+		}
+		changed := r.changed
+		r.mu.Unlock()
+
+		if len(got) >= n {
+			return got
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			kind := "calls"
+			if notification {
+				kind = "notifications"
+			}
+			t.Fatalf("expected %d %s, got %d within %v", n, kind, len(got), Timeout)
+			return nil
+		}
+	}
+}
+
+// AssertJSON compares raw JSON with the JSON encoding of want, ignoring
+// formatting and key order.
+func AssertJSON(t testing.TB, got json.RawMessage, want any) {
+	t.Helper()
+	wantRaw, err := json.Marshal(want)
+	if err != nil {
+		t.Fatalf("cannot encode expectation: %v", err)
+	}
+	var g, w any
+	if err := json.Unmarshal(got, &g); err != nil {
+		t.Fatalf("invalid JSON %s: %v", got, err)
+	}
+	_ = json.Unmarshal(wantRaw, &w)
+	if !reflect.DeepEqual(g, w) {
+		t.Fatalf("JSON mismatch:\n got: %s\nwant: %s", got, wantRaw)
+	}
+}
+
+// AssertRPCError fails unless err is an *rpc.RPCError with code.
+func AssertRPCError(t testing.TB, err error, code int) *rpc.RPCError {
+	t.Helper()
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) {
+		t.Fatalf("expected RPC error %d, got %v", code, err)
+	}
+	if rpcErr.Code != code {
+		t.Fatalf("expected RPC error %d, got %d (%s)", code, rpcErr.Code, rpcErr.Message)
+	}
+	return rpcErr
+}
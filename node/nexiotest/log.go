@@ -0,0 +1,84 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// LogEntry is one recorded log call.
+type LogEntry struct {
+	Level  string // DEBUG, INFO, WARN, ERROR
+	Msg    string
+	Fields map[string]any
+}
+
+// RecordingSink is a transport.LogSink that keeps every entry.
+// Loggers derived via With share the recording.
+type RecordingSink struct {
+	rec    *recording
+	fields map[string]any
+}
+
+type recording struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+func NewRecordingSink() *RecordingSink {
+	return &RecordingSink{rec: &recording{}}
+}
+
+func (s *RecordingSink) record(level, msg string) {
+	fields := make(map[string]any, len(s.fields))
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	s.rec.entries = append(s.rec.entries, LogEntry{Level: level, Msg: msg, Fields: fields})
+}
+
+func (s *RecordingSink) Debug(msg string) { s.record("DEBUG", msg) }
+func (s *RecordingSink) Info(msg string)  { s.record("INFO", msg) }
+func (s *RecordingSink) Warn(msg string)  { s.record("WARN", msg) }
+func (s *RecordingSink) Error(msg string) { s.record("ERROR", msg) }
+
+func (s *RecordingSink) With(key string, value any) transport.LogSink {
+	fields := make(map[string]any, len(s.fields)+1)
+	for k, v := range s.fields {
+		fields[k] = v
+	}
+	fields[key] = value
+	return &RecordingSink{rec: s.rec, fields: fields}
+}
+
+// Entries returns a copy of all recorded entries.
+func (s *RecordingSink) Entries() []LogEntry {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	return append([]LogEntry(nil), s.rec.entries...)
+}
+
+// Find returns the entries at level (any level if "") whose message
+// contains substr.
+func (s *RecordingSink) Find(level, substr string) []LogEntry {
+	var out []LogEntry
+	for _, e := range s.Entries() {
+		if (level == "" || e.Level == level) && strings.Contains(e.Msg, substr) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// Reset drops all entries.
+func (s *RecordingSink) Reset() {
+	s.rec.mu.Lock()
+	defer s.rec.mu.Unlock()
+	s.rec.entries = nil
+}
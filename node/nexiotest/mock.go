@@ -0,0 +1,311 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// MockPeer is a programmable fake remote peer. The code under test gets
+// Conn (e.g. rpc.NewNode(mock.Conn, nil, "", logger)); the mock answers
+// on the other end according to its expectations.
+//
+//	mock := nexiotest.NewMockPeer(t)
+//	first := mock.Expect("payment.process").WithParams(order).Return(receipt)
+//	second := mock.Expect("payment.confirm").ReturnError(402, "declined")
+//	mock.InOrder(first, second)
+//
+// Unmet expectations and unexpected calls fail the test at cleanup
+// (or earlier with Verify). Unexpected calls are answered with
+// ErrCodeMethodNotFound.
+type MockPeer struct {
+	Node *rpc.Node
+	Conn *transport.MemConnection
+
+	t  testing.TB
+	mu sync.Mutex
+
+	expectations []*Expectation
+	unexpected   []Invocation
+	received     []Invocation
+}
+
+// Expectation describes one expected method with its canned answer.
+type Expectation struct {
+	method string
+	params json.RawMessage // nil: any params
+	result any
+	err    *rpc.RPCError
+	delay  time.Duration
+	min    int
+	max    int // -1: unlimited
+	after  *Expectation
+
+	count int // guarded by MockPeer.mu
+}
+
+// NewMockPeer starts the fake peer; it stops and is verified in t.Cleanup.
+func NewMockPeer(t testing.TB) *MockPeer {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	local, remote := transport.NewMemPair()
+	m := &MockPeer{
+		Node: rpc.NewNode(remote, nil, "", nil),
+		Conn: local,
+		t:    t,
+	}
+	m.Node.SetFallback(m.dispatch)
+	go m.Node.Listen(ctx)
+
+	t.Cleanup(func() {
+		m.Verify()
+		cancel()
+		local.Close("test done")
+	})
+	return m
+}
+
+// Expect adds an expectation for method, matched exactly once by default.
+func (m *MockPeer) Expect(method string) *Expectation {
+	e := &Expectation{method: method, min: 1, max: 1}
+	m.mu.Lock()
+	m.expectations = append(m.expectations, e)
+	m.mu.Unlock()
+	return e
+}
+
+// WithParams only matches calls whose params equal the JSON encoding of p.
+func (e *Expectation) WithParams(p any) *Expectation {
+	raw, err := json.Marshal(p)
+	if err != nil {
+		panic(fmt.Sprintf("nexiotest: cannot encode params for %s: %v", e.method, err))
+	}
+	e.params = raw
+	return e
+}
+
+// Return sets the result of the call.
+func (e *Expectation) Return(result any) *Expectation {
+	e.result = result
+	return e
+}
+
+// ReturnError answers with an RPC error.
+func (e *Expectation) ReturnError(code int, message string) *Expectation {
+	e.err = &rpc.RPCError{Code: code, Message: message}
+	return e
+}
+
+// Delay holds the answer back; a cancelled call ends the delay early.
+func (e *Expectation) Delay(d time.Duration) *Expectation {
+	e.delay = d
+	return e
+}
+
+// Times expects exactly n matching calls.
+func (e *Expectation) Times(n int) *Expectation {
+	e.min, e.max = n, n
+	return e
+}
+
+// AnyTimes allows any number of matching calls, including none.
+func (e *Expectation) AnyTimes() *Expectation {
+	e.min, e.max = 0, -1
+	return e
+}
+
+// InOrder requires the expectations to be met in the given order: an
+// expectation only matches once its predecessor got its minimum calls.
+func (m *MockPeer) InOrder(exps ...*Expectation) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i := 1; i < len(exps); i++ {
+		exps[i].after = exps[i-1]
+	}
+}
+
+// Received returns all calls and notifications that reached the mock.
+func (m *MockPeer) Received() []Invocation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Invocation(nil), m.received...)
+}
+
+func (m *MockPeer) dispatch(ctx context.Context, req rpc.Request) (any, error) {
+	inv := Invocation{Method: req.Method, Params: req.Params, Notification: req.IsNotification()}
+
+	m.mu.Lock()
+	m.received = append(m.received, inv)
+	e := m.match(inv)
+	if e == nil {
+		m.unexpected = append(m.unexpected, inv)
+		m.mu.Unlock()
+		return nil, rpc.NewRPCError(rpc.ErrCodeMethodNotFound, "unexpected call "+req.Method)
+	}
+	e.count++
+	m.mu.Unlock()
+
+	if e.delay > 0 {
+		t := time.NewTimer(e.delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return nil, ctx.Err()
+		}
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e.result, nil
+}
+
+// match returns the first open expectation for inv (m.mu held).
+func (m *MockPeer) match(inv Invocation) *Expectation {
+	for _, e := range m.expectations {
+		if e.method != inv.Method || (e.max >= 0 && e.count >= e.max) {
+			continue
+		}
+		if e.params != nil && !jsonEqual(e.params, inv.Params) {
+			continue
+		}
+		if e.after != nil && e.after.count < e.after.min {
+			continue
+		}
+		return e
+	}
+	return nil
+}
+
+// Verify fails the test for unmet expectations and unexpected calls.
+// It runs automatically at cleanup.
+func (m *MockPeer) Verify() {
+	m.t.Helper()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var problems []string
+	for _, e := range m.expectations {
+		if e.count < e.min {
+			desc := e.method
+			if e.params != nil {
+				desc += " " + string(e.params)
+			}
+			problems = append(problems, fmt.Sprintf("missing call %s (%d of %d)", desc, e.count, e.min))
+		}
+	}
+	for _, inv := range m.unexpected {
+		problems = append(problems, fmt.Sprintf("unexpected %s %s", inv.Method, inv.Params))
+	}
+	if len(problems) > 0 {
+		m.t.Errorf("mock peer:\n  %s", strings.Join(problems, "\n  "))
+	}
+	// Report each problem only once.
+	m.unexpected = nil
+	for _, e := range m.expectations {
+		if e.count < e.min {
+			e.min = e.count
+		}
+	}
+}
+
+func jsonEqual(a, b json.RawMessage) bool {
+	var x, y any
+	if json.Unmarshal(a, &x) != nil || json.Unmarshal(b, &y) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+// --- JSON fixtures ---
+
+// Fixture is the language neutral form of a set of expectations:
+//
+//	{
+//	  "ordered": true,
+//	  "expectations": [
+//	    {"method": "payment.process", "params": {"order": "42"}, "result": {"receipt": "r-1"}},
+//	    {"method": "payment.confirm", "error": {"code": 402, "message": "declined"}, "delay": "50ms"},
+//	    {"method": "payment.ping", "times": -1}
+//	  ]
+//	}
+//
+// "times" defaults to 1; -1 means any number of calls.
+type Fixture struct {
+	Ordered      bool                 `json:"ordered,omitempty"`
+	Expectations []FixtureExpectation `json:"expectations"`
+}
+
+type FixtureExpectation struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *rpc.RPCError   `json:"error,omitempty"`
+	Delay  string          `json:"delay,omitempty"` // time.ParseDuration format
+	Times  *int            `json:"times,omitempty"`
+}
+
+// LoadFixture adds the expectations of a JSON fixture file.
+func (m *MockPeer) LoadFixture(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return fmt.Errorf("fixture %s: %w", path, err)
+	}
+	return m.Apply(f)
+}
+
+// Apply adds the expectations of f.
+func (m *MockPeer) Apply(f Fixture) error {
+	exps := make([]*Expectation, 0, len(f.Expectations))
+	for i, fe := range f.Expectations {
+		if fe.Method == "" {
+			return fmt.Errorf("expectation %d: method is required", i)
+		}
+		var delay time.Duration
+		if fe.Delay != "" {
+			d, err := time.ParseDuration(fe.Delay)
+			if err != nil {
+				return fmt.Errorf("expectation %d (%s): %w", i, fe.Method, err)
+			}
+			delay = d
+		}
+
+		e := &Expectation{method: fe.Method, params: fe.Params, err: fe.Error, delay: delay, min: 1, max: 1}
+		if fe.Result != nil {
+			e.result = fe.Result
+		}
+		if fe.Times != nil {
+			if *fe.Times < 0 {
+				e.AnyTimes()
+			} else {
+				e.Times(*fe.Times)
+			}
+		}
+		exps = append(exps, e)
+	}
+
+	m.mu.Lock()
+	m.expectations = append(m.expectations, exps...)
+	m.mu.Unlock()
+	if f.Ordered {
+		m.InOrder(exps...)
+	}
+	return nil
+}
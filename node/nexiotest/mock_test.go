@@ -0,0 +1,106 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+)
+
+// fakeT collects failures and cleanups instead of failing the real test.
+type fakeT struct {
+	testing.TB
+	errs     []string
+	cleanups []func()
+}
+
+func (f *fakeT) Helper() {}
+func (f *fakeT) Errorf(format string, args ...any) {
+	f.errs = append(f.errs, fmt.Sprintf(format, args...))
+}
+func (f *fakeT) Cleanup(fn func()) { f.cleanups = append(f.cleanups, fn) }
+func (f *fakeT) finish() {
+	for i := len(f.cleanups) - 1; i >= 0; i-- {
+		f.cleanups[i]()
+	}
+}
+
+func TestMockPeer_Fixture(t *testing.T) {
+	ctx := context.Background()
+	mock := NewMockPeer(t)
+	if err := mock.LoadFixture("testdata/payment.json"); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.NewNode(mock.Conn, nil, "", nil)
+	go client.Listen(ctx)
+
+	res, err := client.Call(ctx, "payment.process", map[string]any{"amount": 99.5, "order": "42"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertJSON(t, res, map[string]string{"receipt": "r-1"})
+
+	start := time.Now()
+	_, err = client.Call(ctx, "payment.confirm", nil)
+	AssertRPCError(t, err, 402)
+	if time.Since(start) < 10*time.Millisecond {
+		t.Fatal("delay not applied")
+	}
+
+	for i := 0; i < 3; i++ {
+		_ = client.Notify(ctx, "payment.ping", i)
+	}
+	for len(mock.Received()) < 5 {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMockPeer_OrderAndUnexpected(t *testing.T) {
+	ctx := context.Background()
+	ft := &fakeT{}
+	mock := NewMockPeer(ft)
+	first := mock.Expect("payment.process").WithParams([]string{"42"}).Return("ok")
+	second := mock.Expect("payment.confirm").Return(true)
+	mock.Expect("payment.refund").Times(2)
+	mock.InOrder(first, second)
+
+	client := rpc.NewNode(mock.Conn, nil, "", nil)
+	go client.Listen(ctx)
+
+	// Out of order: confirm before process.
+	_, err := client.Call(ctx, "payment.confirm", nil)
+	AssertRPCError(t, err, rpc.ErrCodeMethodNotFound)
+
+	// Wrong params do not match.
+	_, err = client.Call(ctx, "payment.process", []string{"43"})
+	AssertRPCError(t, err, rpc.ErrCodeMethodNotFound)
+
+	if _, err := client.Call(ctx, "payment.process", []string{"42"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Call(ctx, "payment.confirm", nil); err != nil {
+		t.Fatal(err)
+	}
+	_, _ = client.Call(ctx, "payment.refund", nil)
+
+	ft.finish()
+	got := strings.Join(ft.errs, "\n")
+	for _, want := range []string{
+		"missing call payment.refund (1 of 2)",
+		"unexpected payment.confirm null",
+		`unexpected payment.process ["43"]`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "missing call payment.process") {
+		t.Errorf("met expectation reported:\n%s", got)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestPeerPair_RecordAndAssert(t *testing.T) {
+	p := NewPeerPair(t)
+	calls := Record(p.Server, "order.create", map[string]string{"id": "42"})
+	events := Record(p.Client, "order.created", nil)
+
+	p.Server.Register("order.touch", func(ctx context.Context, _ json.RawMessage) (any, error) {
+		return nil, p.Server.Notify(ctx, "order.created", map[string]any{"id": "42", "qty": 2})
+	})
+
+	res, err := p.Client.Call(p.Ctx, "order.create", []int{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	AssertJSON(t, res, map[string]string{"id": "42"})
+	AssertJSON(t, calls.ExpectCalls(t, 1)[0].Params, []int{2})
+
+	if _, err := p.Client.Call(p.Ctx, "order.touch", nil); err != nil {
+		t.Fatal(err)
+	}
+	n := events.ExpectNotifications(t, 1)
+	AssertJSON(t, n[0].Params, map[string]any{"qty": 2, "id": "42"})
+
+	_, err = p.Client.Call(p.Ctx, "order.unknown", nil)
+	AssertRPCError(t, err, rpc.ErrCodeMethodNotFound)
+
+	p.Disconnect()
+	_, err = p.Client.Call(p.Ctx, "order.create", nil)
+	if !rpc.IsConnectionError(err) {
+		t.Fatalf("expected connection error after Disconnect, got %v", err)
+	}
+}
+
+func TestFakeClock_ReconnectBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := transport.NewMemNetwork()
+	mem := net.Provider("client")
+	var dials atomic.Int32
+	dialer := transport.DialerFunc(func(ctx context.Context, addr string) (transport.Connection, error) {
+		dials.Add(1)
+		return mem.Dial(ctx, addr)
+	})
+
+	clock := NewFakeClock(time.Time{})
+	log := NewRecordingSink()
+	node := rpc.NewNode(nil, dialer, "svc", log)
+	node.SetClock(clock)
+	node.SetReconnectBackoff(time.Second, 4*time.Second)
+	go node.Listen(ctx)
+
+	// Nobody listens: every dial fails and the backoff doubles up to 4s.
+	for i, wait := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 4 * time.Second} {
+		clock.BlockUntil(1)
+		if got := dials.Load(); got != int32(i+1) {
+			t.Fatalf("attempt %d: %d dials", i+1, got)
+		}
+		clock.Advance(wait - time.Millisecond)
+		if clock.Waiters() != 1 {
+			t.Fatalf("attempt %d: backoff shorter than %v", i+1, wait)
+		}
+		clock.Advance(time.Millisecond)
+	}
+
+	if len(log.Find("ERROR", "Failed, next attempt")) < 4 {
+		t.Fatalf("backoff not logged: %+v", log.Entries())
+	}
+	if e := log.Find("INFO", "Try Reconnect"); len(e) == 0 || e[0].Fields["dialAddr"] != "svc" {
+		t.Fatalf("missing dial log fields: %+v", e)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package nexiotest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// PeerPair is two listening Nodes connected over memory.
+// Which side is "client" only matters for naming; both are peers.
+type PeerPair struct {
+	Client, Server         *rpc.Node
+	ClientConn, ServerConn *transport.MemConnection
+
+	// Ctx ends when the test ends.
+	Ctx context.Context
+}
+
+// NewPeerPair starts both Nodes. They stop and the connection is closed
+// in t.Cleanup.
+func NewPeerPair(t testing.TB) *PeerPair {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	clientConn, serverConn := transport.NewMemPair()
+	p := &PeerPair{
+		Client:     rpc.NewNode(clientConn, nil, "", nil),
+		Server:     rpc.NewNode(serverConn, nil, "", nil),
+		ClientConn: clientConn,
+		ServerConn: serverConn,
+		Ctx:        ctx,
+	}
+	go p.Client.Listen(ctx)
+	go p.Server.Listen(ctx)
+
+	t.Cleanup(func() {
+		cancel()
+		clientConn.Close("test done")
+	})
+	return p
+}
+
+// Disconnect closes the connection; pending calls on both sides fail.
+func (p *PeerPair) Disconnect() {
+	p.ClientConn.Close("disconnect")
+}
@@ -0,0 +1,43 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package outbox delivers notifications at least once, across reconnects
+and process restarts.
+
+rpc.Node.Notify is fire-and-forget and fails while the connection is
+down. An Outbox instead appends every message to a local file first and
+delivers it as an "outbox.deliver" Call; only the peer's answer removes
+it from the file. Messages go out one at a time in the order they were
+added.
+
+Sender:
+
+	ob, err := outbox.Open("payment.outbox", node, logger)
+	if err != nil {
+	    return err
+	}
+	defer ob.Close()
+	ob.Start(ctx)
+
+	id, err := ob.Notify(ctx, "payment.confirmed", confirmation)
+
+Receiver, one Inbox shared by all Nodes of the service:
+
+	inbox, err := outbox.OpenInbox("payment.inbox", logger) // or NewInbox(logger)
+	inbox.Handle("payment.confirmed", func(ctx context.Context, p json.RawMessage) error {
+	    // ...
+	})
+	inbox.Attach(node) // for every accepted connection
+
+A message is acknowledged when its handler returns nil. A retried message
+whose id the Inbox has already handled is acknowledged without running
+the handler again. The Inbox remembers the last Window ids; with
+OpenInbox they also survive a restart of the receiver.
+
+A message the receiver rejects stays at the head of the queue and is
+retried every RetryInterval. Rejections as unknown method or invalid
+params are final: such a message, or one rejected MaxAttempts times, is
+dropped and handed to the DeadLetter hook.
+*/
+package outbox
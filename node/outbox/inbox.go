@@ -0,0 +1,212 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Handler processes one delivered message. Returning an error makes the
+// sender retry it later.
+type Handler func(ctx context.Context, params json.RawMessage) error
+
+// Inbox is the receiving side. It runs the handler of every delivered
+// message once per id, as far as it remembers ids (Window).
+type Inbox struct {
+	Window int // number of handled ids remembered for deduplication
+	Log    transport.LogSink
+
+	mu       sync.Mutex
+	handlers map[string]Handler
+	seen     map[string]struct{}
+	order    []string
+	running  map[string]chan struct{}
+	file     *os.File // nil for a memory-only Inbox
+	path     string
+	appended int // ids written since the last compact
+}
+
+func NewInbox(logger transport.LogSink) *Inbox {
+	in := &Inbox{
+		Window:   10000,
+		Log:      &transport.SilentLogger{},
+		handlers: make(map[string]Handler),
+		seen:     make(map[string]struct{}),
+		running:  make(map[string]chan struct{}),
+	}
+	if logger != nil {
+		in.Log = logger
+	}
+	return in
+}
+
+// OpenInbox is NewInbox with the handled ids kept in the file at path,
+// so that deduplication survives a restart.
+func OpenInbox(path string, logger transport.LogSink) (*Inbox, error) {
+	in := NewInbox(logger)
+	in.path = path
+
+	f, err := os.Open(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			if id := strings.TrimSpace(s.Text()); id != "" {
+				in.remember(id)
+			}
+		}
+		f.Close()
+		if err := s.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := in.compact(); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// Handle registers h for method.
+func (in *Inbox) Handle(method string, h Handler) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	in.handlers[method] = h
+}
+
+// Attach lets the peer of node deliver to this Inbox.
+func (in *Inbox) Attach(node *rpc.Node) {
+	node.Register(MethodDeliver, func(ctx context.Context, p json.RawMessage) (any, error) {
+		m, err := rpc.Bind[Message](p)
+		if err != nil {
+			return nil, err
+		}
+		if m.ID == "" {
+			return nil, rpc.NewRPCError(rpc.ErrCodeInvalidParams, "id is required")
+		}
+		if err := in.deliver(ctx, m); err != nil {
+			return nil, err
+		}
+		return true, nil
+	})
+}
+
+// Close closes the id file of an OpenInbox.
+func (in *Inbox) Close() error {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.file == nil {
+		return nil
+	}
+	err := in.file.Close()
+	in.file = nil
+	return err
+}
+
+func (in *Inbox) deliver(ctx context.Context, m Message) error {
+	in.mu.Lock()
+	for {
+		if _, ok := in.seen[m.ID]; ok {
+			in.mu.Unlock()
+			in.Log.With("id", m.ID).Debug("Duplicate delivery acknowledged")
+			return nil
+		}
+		// A retry may arrive while the first attempt is still running.
+		done, ok := in.running[m.ID]
+		if !ok {
+			break
+		}
+		in.mu.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		in.mu.Lock()
+	}
+	h, ok := in.handlers[m.Method]
+	if !ok {
+		in.mu.Unlock()
+		return rpc.NewRPCError(rpc.ErrCodeMethodNotFound, m.Method)
+	}
+	done := make(chan struct{})
+	in.running[m.ID] = done
+	in.mu.Unlock()
+
+	err := h(ctx, m.Params)
+
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	delete(in.running, m.ID)
+	close(done)
+	if err != nil {
+		return err
+	}
+	in.remember(m.ID)
+	if in.file != nil {
+		if _, werr := in.file.WriteString(m.ID + "\n"); werr != nil {
+			in.Log.With("id", m.ID).With("error", werr).Error("Storing handled id failed")
+		}
+		// Rewrite the file once per Window ids, it holds at most two windows.
+		in.appended++
+		if in.appended >= in.Window {
+			if cerr := in.compact(); cerr != nil {
+				in.Log.With("error", cerr).Error("Compacting inbox failed")
+			}
+		}
+	}
+	return nil
+}
+
+// remember adds id and forgets the oldest ids beyond Window. Callers hold mu.
+func (in *Inbox) remember(id string) {
+	if _, ok := in.seen[id]; ok {
+		return
+	}
+	in.seen[id] = struct{}{}
+	in.order = append(in.order, id)
+	for len(in.order) > in.Window {
+		delete(in.seen, in.order[0])
+		in.order = in.order[1:]
+	}
+}
+
+// compact rewrites the id file with the remembered ids. Callers hold mu
+// (or own in exclusively).
+func (in *Inbox) compact() error {
+	tmp := in.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, id := range in.order {
+		w.WriteString(id + "\n")
+	}
+	if err := errors.Join(w.Flush(), f.Sync(), f.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, in.path); err != nil {
+		return err
+	}
+
+	if in.file != nil {
+		in.file.Close()
+	}
+	in.file, err = os.OpenFile(in.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	in.appended = 0
+	return err
+}
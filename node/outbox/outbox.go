@@ -0,0 +1,343 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// MethodDeliver is the wire method of a delivery; its result is the ack.
+const MethodDeliver = "outbox.deliver"
+
+// ErrClosed is returned by Notify after Close.
+var ErrClosed = errors.New("outbox closed")
+
+// Message is one durable notification, also the params of MethodDeliver.
+type Message struct {
+	ID     string          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Time   time.Time       `json:"time"`
+}
+
+// record is one line of the outbox file.
+type record struct {
+	Op  string   `json:"op"` // "put", "ack" or "dead"
+	Msg *Message `json:"msg,omitempty"`
+	ID  string   `json:"id,omitempty"`
+}
+
+// Outbox is the sending side. It belongs to one (usually reconnecting) Node.
+type Outbox struct {
+	node *rpc.Node
+	path string
+
+	RetryInterval time.Duration // between rounds while messages are pending
+	AckTimeout    time.Duration // per delivery
+	CompactAfter  int           // acks before the file is rewritten
+	MaxAttempts   int           // rejections before a message is dead; 0 = retry forever
+	Log           transport.LogSink
+
+	// DeadLetter receives messages the peer will never accept: rejected
+	// as unknown method or invalid params, or MaxAttempts times. They
+	// are removed from the queue so the ones behind them go out.
+	DeadLetter func(m *Message, err error)
+
+	mu       sync.Mutex
+	file     *os.File
+	pending  []*Message
+	acks     int
+	attempts int // rejections of pending[0]
+
+	kick chan struct{}
+}
+
+// Open loads the pending messages from path (created if missing) and
+// appends new ones to it. Deliveries start with Start.
+func Open(path string, node *rpc.Node, logger transport.LogSink) (*Outbox, error) {
+	o := &Outbox{
+		node:          node,
+		path:          path,
+		RetryInterval: time.Second,
+		AckTimeout:    10 * time.Second,
+		CompactAfter:  1000,
+		Log:           &transport.SilentLogger{},
+		kick:          make(chan struct{}, 1),
+	}
+	if logger != nil {
+		o.Log = logger
+	}
+
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+	// Start from a file holding only the pending messages.
+	if err := o.compact(); err != nil {
+		return nil, err
+	}
+	if len(o.pending) > 0 {
+		o.Log.With("pending", len(o.pending)).Info("Outbox loaded")
+	}
+
+	node.OnConnect(func(ctx context.Context) { o.wake() })
+	return o, nil
+}
+
+func (o *Outbox) load() error {
+	f, err := os.Open(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	index := make(map[string]int)
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var rec record
+			switch {
+			case json.Unmarshal(line, &rec) != nil:
+				// Typically the torn last line of a crash.
+				o.Log.With("path", o.path).Warn("Broken outbox record skipped")
+			case rec.Op == "put" && rec.Msg != nil:
+				index[rec.Msg.ID] = len(o.pending)
+				o.pending = append(o.pending, rec.Msg)
+			case rec.Op == "ack" || rec.Op == "dead":
+				if i, ok := index[rec.ID]; ok {
+					o.pending[i] = nil
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	kept := o.pending[:0]
+	for _, m := range o.pending {
+		if m != nil {
+			kept = append(kept, m)
+		}
+	}
+	o.pending = kept
+	return nil
+}
+
+// Notify stores the message and returns its id; delivery happens in the
+// background. Unlike rpc.Node.Notify it succeeds while disconnected.
+func (o *Outbox) Notify(ctx context.Context, method string, params any) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return "", err
+	}
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	m := &Message{ID: hex.EncodeToString(id[:]), Method: method, Params: raw, Time: time.Now()}
+
+	o.mu.Lock()
+	if o.file == nil {
+		o.mu.Unlock()
+		return "", ErrClosed
+	}
+	err = o.append(record{Op: "put", Msg: m})
+	if err == nil {
+		o.pending = append(o.pending, m)
+	}
+	o.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+
+	o.wake()
+	return m.ID, nil
+}
+
+// Pending returns the number of messages not acknowledged yet.
+func (o *Outbox) Pending() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.pending)
+}
+
+// Start delivers pending messages until ctx ends: right away, after
+// every Notify and reconnect, and every RetryInterval while some are left.
+func (o *Outbox) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(o.RetryInterval)
+		defer ticker.Stop()
+		for {
+			o.flush(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-o.kick:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close stops accepting messages. Pending ones stay in the file.
+func (o *Outbox) Close() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return nil
+	}
+	err := o.file.Close()
+	o.file = nil
+	return err
+}
+
+func (o *Outbox) wake() {
+	select {
+	case o.kick <- struct{}{}:
+	default:
+	}
+}
+
+// flush delivers in order until the queue is empty or a delivery fails.
+// A rejected message is retried in the next round and blocks the ones
+// behind it, so the receiver never sees them out of order, unless it is
+// dead (see DeadLetter).
+func (o *Outbox) flush(ctx context.Context) {
+	for ctx.Err() == nil && o.node.Connected() {
+		o.mu.Lock()
+		if len(o.pending) == 0 || o.file == nil {
+			o.mu.Unlock()
+			return
+		}
+		m := o.pending[0]
+		o.mu.Unlock()
+
+		cctx, cancel := context.WithTimeout(ctx, o.AckTimeout)
+		_, err := o.node.Call(cctx, MethodDeliver, m)
+		cancel()
+		if err != nil {
+			log := o.Log.With("id", m.ID).With("method", m.Method).With("error", err)
+			if rpc.IsConnectionError(err) {
+				log.Debug("Delivery interrupted, waiting for the connection")
+				return
+			}
+			if !o.dead(m, err) {
+				log.Warn("Delivery rejected, will retry")
+				return
+			}
+			log.Error("Delivery rejected for good, message dropped")
+			if err := o.remove(m, "dead"); err != nil {
+				o.Log.With("id", m.ID).With("error", err).Error("Storing dead letter failed")
+				return
+			}
+			if o.DeadLetter != nil {
+				o.DeadLetter(m, err)
+			}
+			continue
+		}
+
+		if err := o.remove(m, "ack"); err != nil {
+			o.Log.With("id", m.ID).With("error", err).Error("Storing ack failed")
+			return
+		}
+	}
+}
+
+// dead counts a rejection of m and reports whether to give up on it.
+func (o *Outbox) dead(m *Message, err error) bool {
+	var rpcErr *rpc.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case rpc.ErrCodeMethodNotFound, rpc.ErrCodeInvalidParams:
+			return true
+		}
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.attempts++
+	return o.MaxAttempts > 0 && o.attempts >= o.MaxAttempts
+}
+
+// remove takes the delivered or dead m off the queue, recording op.
+func (o *Outbox) remove(m *Message, op string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.file == nil {
+		return ErrClosed
+	}
+	if err := o.append(record{Op: op, ID: m.ID}); err != nil {
+		return err
+	}
+	if len(o.pending) > 0 && o.pending[0] == m {
+		o.pending = o.pending[1:]
+		o.attempts = 0
+	}
+	o.acks++
+	if o.acks >= o.CompactAfter {
+		return o.compact()
+	}
+	return nil
+}
+
+// append writes one record and syncs it to disk. Callers hold mu.
+func (o *Outbox) append(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	if _, err := o.file.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return o.file.Sync()
+}
+
+// compact replaces the file by one holding only the pending messages.
+// Callers hold mu (or own o exclusively).
+func (o *Outbox) compact() error {
+	tmp := o.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, m := range o.pending {
+		line, _ := json.Marshal(record{Op: "put", Msg: m})
+		w.Write(append(line, '\n'))
+	}
+	if err := errors.Join(w.Flush(), f.Sync(), f.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, o.path); err != nil {
+		return err
+	}
+
+	if o.file != nil {
+		o.file.Close()
+	}
+	o.file, err = os.OpenFile(o.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	o.acks = 0
+	return err
+}
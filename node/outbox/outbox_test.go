@@ -0,0 +1,274 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// serve attaches inbox to every Node accepted on addr.
+func serve(ctx context.Context, p *transport.MemProvider, addr string, inbox *Inbox) {
+	found := make(chan transport.Connection)
+	go p.Listen(ctx, addr, found)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := rpc.NewNode(conn, nil, "", nil)
+				inbox.Attach(node)
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+type received struct {
+	mu  sync.Mutex
+	got []string
+}
+
+func (r *received) handler(ctx context.Context, p json.RawMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.got = append(r.got, string(p))
+	return nil
+}
+
+func (r *received) list() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.got...)
+}
+
+func TestOutbox_RestartAndReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	path := filepath.Join(t.TempDir(), "outbox")
+
+	// Written while the peer is unreachable, then the process "dies".
+	offline := rpc.NewNode(nil, nil, "", nil)
+	ob, err := Open(path, offline, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{"a", "b"} {
+		if _, err := ob.Notify(ctx, "payment.confirmed", p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ob.Close()
+
+	net := transport.NewMemNetwork()
+	var r received
+	inbox := NewInbox(nil)
+	inbox.Handle("payment.confirmed", r.handler)
+	serve(ctx, net.Provider("server"), "svc", inbox)
+
+	node := rpc.NewNode(nil, net.Provider("client"), "svc", nil)
+	node.SetReconnectBackoff(time.Millisecond, 5*time.Millisecond)
+	ob, err = Open(path, node, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ob.Close()
+	if ob.Pending() != 2 {
+		t.Fatalf("expected 2 pending after restart, got %d", ob.Pending())
+	}
+	ob.RetryInterval = 5 * time.Millisecond
+	ob.Start(ctx)
+	go node.Listen(ctx)
+
+	waitFor(t, "delivery after restart", func() bool { return ob.Pending() == 0 })
+
+	// Notifies during a partition go out after the connection is back.
+	net.Partition("client", "server")
+	waitFor(t, "disconnect", func() bool { return !node.Connected() })
+	ob.Notify(ctx, "payment.confirmed", "c")
+	net.Heal("client", "server")
+	waitFor(t, "delivery after reconnect", func() bool { return ob.Pending() == 0 })
+
+	got := r.list()
+	if len(got) != 3 || got[0] != `"a"` || got[1] != `"b"` || got[2] != `"c"` {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+
+	// Acked messages are gone for good.
+	ob.Close()
+	again, err := Open(path, offline, nil)
+	if err != nil || again.Pending() != 0 {
+		t.Fatalf("expected empty outbox, got %d (%v)", again.Pending(), err)
+	}
+	again.Close()
+}
+
+func TestOutbox_RejectedKeepsOrder(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	client := rpc.NewNode(clientConn, nil, "", nil)
+	server := rpc.NewNode(serverConn, nil, "", nil)
+
+	var r received
+	failures := 2
+	inbox := NewInbox(nil)
+	inbox.Handle("order.placed", func(ctx context.Context, p json.RawMessage) error {
+		if string(p) == `1` && failures > 0 {
+			failures--
+			return errors.New("database busy")
+		}
+		return r.handler(ctx, p)
+	})
+	inbox.Attach(server)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+
+	ob, err := Open(filepath.Join(t.TempDir(), "outbox"), client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ob.Close()
+	ob.RetryInterval = 5 * time.Millisecond
+	for i := 1; i <= 3; i++ {
+		ob.Notify(ctx, "order.placed", i)
+	}
+	ob.Start(ctx)
+
+	waitFor(t, "deliveries", func() bool { return ob.Pending() == 0 })
+	if got := r.list(); len(got) != 3 || got[0] != "1" || got[2] != "3" {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestOutbox_DeadLetter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	client := rpc.NewNode(clientConn, nil, "", nil)
+	server := rpc.NewNode(serverConn, nil, "", nil)
+
+	var r received
+	inbox := NewInbox(nil)
+	inbox.Handle("order.placed", func(ctx context.Context, p json.RawMessage) error {
+		if string(p) == `"poison"` {
+			return errors.New("cannot handle")
+		}
+		return r.handler(ctx, p)
+	})
+	inbox.Attach(server)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+
+	path := filepath.Join(t.TempDir(), "outbox")
+	ob, err := Open(path, client, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ob.RetryInterval = 5 * time.Millisecond
+	ob.MaxAttempts = 3
+	var mu sync.Mutex
+	var dead []string
+	ob.DeadLetter = func(m *Message, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		dead = append(dead, m.Method+" "+string(m.Params))
+	}
+	ob.Notify(ctx, "order.cancelled", "unknown method")
+	ob.Notify(ctx, "order.placed", "poison")
+	ob.Notify(ctx, "order.placed", "ok")
+	ob.Start(ctx)
+
+	waitFor(t, "queue drained", func() bool { return ob.Pending() == 0 })
+	if got := r.list(); len(got) != 1 || got[0] != `"ok"` {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+	mu.Lock()
+	if len(dead) != 2 || dead[0] != `order.cancelled "unknown method"` || dead[1] != `order.placed "poison"` {
+		t.Fatalf("unexpected dead letters %v", dead)
+	}
+	mu.Unlock()
+
+	// Dead messages do not come back after a restart.
+	ob.Close()
+	again, err := Open(path, rpc.NewNode(nil, nil, "", nil), nil)
+	if err != nil || again.Pending() != 0 {
+		t.Fatalf("expected empty outbox, got %d (%v)", again.Pending(), err)
+	}
+	again.Close()
+}
+
+func TestInbox_Dedupe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	path := filepath.Join(t.TempDir(), "inbox")
+
+	deliver := func(inbox *Inbox, m Message) {
+		t.Helper()
+		clientConn, serverConn := transport.NewMemPair()
+		client := rpc.NewNode(clientConn, nil, "", nil)
+		server := rpc.NewNode(serverConn, nil, "", nil)
+		inbox.Attach(server)
+		go server.Listen(ctx)
+		go client.Listen(ctx)
+		if res, err := client.Call(ctx, MethodDeliver, m); err != nil || string(res) != "true" {
+			t.Fatalf("deliver: %s %v", res, err)
+		}
+	}
+
+	var r received
+	inbox, err := OpenInbox(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	inbox.Handle("payment.confirmed", r.handler)
+	m := Message{ID: "m1", Method: "payment.confirmed", Params: json.RawMessage(`"x"`)}
+	deliver(inbox, m)
+	deliver(inbox, m) // lost ack, the sender retries
+	inbox.Close()
+
+	// The ids survive a restart of the receiver.
+	inbox, err = OpenInbox(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer inbox.Close()
+	inbox.Handle("payment.confirmed", r.handler)
+	deliver(inbox, m)
+	deliver(inbox, Message{ID: "m2", Method: "payment.confirmed", Params: json.RawMessage(`"y"`)})
+
+	if got := r.list(); len(got) != 2 || got[0] != `"x"` || got[1] != `"y"` {
+		t.Fatalf("unexpected deliveries %v", got)
+	}
+}
+
+func TestInbox_Window(t *testing.T) {
+	inbox := NewInbox(nil)
+	inbox.Window = 2
+	for _, id := range []string{"a", "b", "c"} {
+		inbox.remember(id)
+	}
+	if _, ok := inbox.seen["a"]; ok || len(inbox.seen) != 2 {
+		t.Fatalf("expected only the last 2 ids, got %v", inbox.seen)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package pubsub adds publish/subscribe topics on top of rpc.Node.
+
+The publishing service shares one Broker between all its Nodes. A peer
+subscribes with a Client on its own Node; the Broker then delivers
+matching events as "pubsub.event" notifications.
+
+Topics are dot separated. Patterns support "*" for one segment and ">"
+as the last segment for the rest of the topic ("order.>").
+
+Publisher:
+
+	broker := pubsub.NewBroker(logger)
+	broker.Attach(node) // for every accepted connection
+	broker.Publish(ctx, "order.update", update)
+
+Subscriber:
+
+	client := pubsub.NewClient(node)
+	client.Subscribe(ctx, "order.*", func(ctx context.Context, topic string, p json.RawMessage) {
+	    // ...
+	})
+
+Subscriptions of a peer are dropped when its connection is lost; the
+Client re-establishes them after every reconnect.
+*/
+package pubsub
@@ -0,0 +1,247 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Wire methods of the pub/sub protocol.
+const (
+	MethodSubscribe   = "pubsub.subscribe"
+	MethodUnsubscribe = "pubsub.unsubscribe"
+	MethodEvent       = "pubsub.event"
+)
+
+// Event is the payload of a "pubsub.event" notification.
+type Event struct {
+	Topic   string          `json:"topic"`
+	Payload json.RawMessage `json:"payload,omitempty"`
+}
+
+type subscription struct {
+	Pattern string `json:"pattern"`
+}
+
+// Match reports whether topic matches pattern. Topics are dot separated;
+// "*" matches exactly one segment, ">" (last segment only) matches one or more.
+//
+//	Match("order.*", "order.update")        // true
+//	Match("order.>", "order.eu.update")     // true
+//	Match("order.*", "order.eu.update")     // false
+func Match(pattern, topic string) bool {
+	ps := strings.Split(pattern, ".")
+	ts := strings.Split(topic, ".")
+	for i, p := range ps {
+		if p == ">" && i == len(ps)-1 {
+			return len(ts) > i
+		}
+		if i >= len(ts) || (p != "*" && p != ts[i]) {
+			return false
+		}
+	}
+	return len(ps) == len(ts)
+}
+
+// --- Publisher side ---
+
+// Broker tracks which peers subscribed to which topics and delivers
+// published events to them. One Broker is shared by all Nodes of a service.
+type Broker struct {
+	mu   sync.RWMutex
+	subs map[*rpc.Node]map[string]struct{}
+
+	Log transport.LogSink
+}
+
+func NewBroker(logger transport.LogSink) *Broker {
+	b := &Broker{
+		subs: make(map[*rpc.Node]map[string]struct{}),
+		Log:  &transport.SilentLogger{},
+	}
+	if logger != nil {
+		b.Log = logger
+	}
+	return b
+}
+
+// Attach lets the peer of node subscribe to this Broker.
+// Its subscriptions are dropped when the connection is lost.
+func (b *Broker) Attach(node *rpc.Node) {
+	node.Register(MethodSubscribe, func(ctx context.Context, p json.RawMessage) (any, error) {
+		sub, err := rpc.Bind[subscription](p)
+		if err != nil {
+			return nil, err
+		}
+		if sub.Pattern == "" {
+			return nil, rpc.NewRPCError(rpc.ErrCodeInvalidParams, "pattern is required")
+		}
+		b.mu.Lock()
+		if b.subs[node] == nil {
+			b.subs[node] = make(map[string]struct{})
+		}
+		b.subs[node][sub.Pattern] = struct{}{}
+		b.mu.Unlock()
+		b.Log.With("pattern", sub.Pattern).Debug("Subscribed")
+		return true, nil
+	})
+
+	node.Register(MethodUnsubscribe, func(ctx context.Context, p json.RawMessage) (any, error) {
+		sub, err := rpc.Bind[subscription](p)
+		if err != nil {
+			return nil, err
+		}
+		b.mu.Lock()
+		delete(b.subs[node], sub.Pattern)
+		b.mu.Unlock()
+		return true, nil
+	})
+
+	node.OnDisconnect(func(err error) {
+		b.Detach(node)
+	})
+}
+
+// Detach removes all subscriptions of node.
+func (b *Broker) Detach(node *rpc.Node) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.subs, node)
+}
+
+// Publish delivers payload to every peer with a matching subscription.
+// Each peer gets the event at most once, even if several patterns match.
+func (b *Broker) Publish(ctx context.Context, topic string, payload any) error {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	ev := Event{Topic: topic, Payload: raw}
+
+	var targets []*rpc.Node
+	b.mu.RLock()
+	for node, patterns := range b.subs {
+		for p := range patterns {
+			if Match(p, topic) {
+				targets = append(targets, node)
+				break
+			}
+		}
+	}
+	b.mu.RUnlock()
+
+	var errs []error
+	for _, node := range targets {
+		if err := node.Notify(ctx, MethodEvent, ev); err != nil {
+			b.Log.With("topic", topic).With("error", err).Warn("Publish failed")
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Subscribers returns the number of peers with at least one subscription.
+func (b *Broker) Subscribers() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	n := 0
+	for _, patterns := range b.subs {
+		if len(patterns) > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// --- Subscriber side ---
+
+// Handler is called for every event matching a subscription.
+type Handler func(ctx context.Context, topic string, payload json.RawMessage)
+
+// Client subscribes to topics on the remote Broker of its Node.
+// Subscriptions are re-established automatically after a reconnect.
+type Client struct {
+	node *rpc.Node
+
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewClient(node *rpc.Node) *Client {
+	c := &Client{
+		node:     node,
+		handlers: make(map[string][]Handler),
+	}
+
+	node.Register(MethodEvent, func(ctx context.Context, p json.RawMessage) (any, error) {
+		ev, err := rpc.Bind[Event](p)
+		if err != nil {
+			return nil, err
+		}
+		c.dispatch(ctx, ev)
+		return nil, nil
+	})
+
+	node.OnConnect(c.resubscribe)
+	return c
+}
+
+// Subscribe registers h for pattern and announces the pattern to the peer.
+// The handler stays registered even if the announcement fails; it will be
+// retried on the next reconnect.
+func (c *Client) Subscribe(ctx context.Context, pattern string, h Handler) error {
+	c.mu.Lock()
+	c.handlers[pattern] = append(c.handlers[pattern], h)
+	c.mu.Unlock()
+
+	_, err := c.node.Call(ctx, MethodSubscribe, subscription{Pattern: pattern})
+	return err
+}
+
+// Unsubscribe removes all handlers for pattern locally and on the peer.
+func (c *Client) Unsubscribe(ctx context.Context, pattern string) error {
+	c.mu.Lock()
+	delete(c.handlers, pattern)
+	c.mu.Unlock()
+
+	_, err := c.node.Call(ctx, MethodUnsubscribe, subscription{Pattern: pattern})
+	return err
+}
+
+func (c *Client) dispatch(ctx context.Context, ev Event) {
+	c.mu.RLock()
+	var matched []Handler
+	for p, hs := range c.handlers {
+		if Match(p, ev.Topic) {
+			matched = append(matched, hs...)
+		}
+	}
+	c.mu.RUnlock()
+
+	for _, h := range matched {
+		h(ctx, ev.Topic, ev.Payload)
+	}
+}
+
+func (c *Client) resubscribe(ctx context.Context) {
+	c.mu.RLock()
+	patterns := make([]string, 0, len(c.handlers))
+	for p := range c.handlers {
+		patterns = append(patterns, p)
+	}
+	c.mu.RUnlock()
+
+	for _, p := range patterns {
+		if _, err := c.node.Call(ctx, MethodSubscribe, subscription{Pattern: p}); err != nil {
+			c.node.Log.With("pattern", p).With("error", err).Warn("Resubscribe failed")
+		}
+	}
+}
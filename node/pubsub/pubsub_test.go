@@ -0,0 +1,147 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestMatch(t *testing.T) {
+	cases := []struct {
+		pattern, topic string
+		want           bool
+	}{
+		{"order.update", "order.update", true},
+		{"order.*", "order.update", true},
+		{"order.*", "order.eu.update", false},
+		{"order.>", "order.eu.update", true},
+		{"order.>", "order", false},
+		{"*.update", "payment.update", true},
+		{"order", "order.update", false},
+	}
+	for _, c := range cases {
+		if got := Match(c.pattern, c.topic); got != c.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", c.pattern, c.topic, got, c.want)
+		}
+	}
+}
+
+func TestPublishSubscribe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := rpc.NewNode(serverConn, nil, "", nil)
+	clientNode := rpc.NewNode(clientConn, nil, "", nil)
+
+	broker := NewBroker(nil)
+	broker.Attach(serverNode)
+	client := NewClient(clientNode)
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	events := make(chan string, 10)
+	err := client.Subscribe(ctx, "order.*", func(ctx context.Context, topic string, p json.RawMessage) {
+		events <- topic + " " + string(p)
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	broker.Publish(ctx, "payment.done", "ignored")
+	broker.Publish(ctx, "order.update", "paid")
+
+	select {
+	case got := <-events:
+		if got != `order.update "paid"` {
+			t.Errorf("Unexpected event %s", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("No event received")
+	}
+
+	// Losing the connection drops the subscriptions on the broker side.
+	close(clientConn.Out)
+	deadline := time.Now().Add(time.Second)
+	for broker.Subscribers() != 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := broker.Subscribers(); n != 0 {
+		t.Errorf("Expected subscriptions to be cleaned up, got %d", n)
+	}
+}
+
+func TestResubscribeAfterReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := transport.NewMemNetwork()
+	broker := NewBroker(nil)
+	found := make(chan transport.Connection)
+	go net.Provider("server").Listen(ctx, "broker", found)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := rpc.NewNode(conn, nil, "", nil)
+				broker.Attach(node)
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	clientNode := rpc.NewNode(nil, net.Provider("client"), "broker", nil)
+	clientNode.SetReconnectBackoff(time.Millisecond, 5*time.Millisecond)
+	var connects atomic.Int32
+	clientNode.OnConnect(func(ctx context.Context) { connects.Add(1) })
+	client := NewClient(clientNode)
+	go clientNode.Listen(ctx)
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(2 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timeout waiting for %s", what)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	waitFor("connect", func() bool { return connects.Load() == 1 })
+	events := make(chan string, 10)
+	err := client.Subscribe(ctx, "order.*", func(ctx context.Context, topic string, p json.RawMessage) {
+		events <- topic
+	})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	net.Disconnect("client")
+	waitFor("reconnect", func() bool { return connects.Load() == 2 })
+
+	// The broker may still hold the dead Node for a moment, so publish
+	// until the re-established subscription delivers.
+	deadline := time.After(2 * time.Second)
+	for {
+		broker.Publish(ctx, "order.update", "after reconnect")
+		select {
+		case got := <-events:
+			if got != "order.update" {
+				t.Errorf("Unexpected event %s", got)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("No event received after reconnect")
+		}
+	}
+}
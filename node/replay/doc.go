@@ -0,0 +1,30 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package replay captures the frames of a connection into a file and plays
+them back against a local Node.
+
+Recording in staging (JSON lines, see middleware.Frame):
+
+	rec, err := replay.Record(conn, "order-payment.jsonl")
+	node := rpc.NewNode(rec, nil, "", logger)
+
+In a test the Replayer plays the recorded side ("out" frames are sent,
+"in" frames are expected from the Node under test):
+
+	frames, _ := replay.LoadFile("testdata/order-payment.jsonl")
+	rp := replay.NewReplayer(frames) // recorded at the client, so replay the client
+	node := rpc.NewNode(rp.Conn(), nil, "", nil)
+	registerPaymentHandlers(node)
+	go node.Listen(ctx)
+
+	if err := rp.Run(ctx); err != nil {
+		t.Fatal(err) // *Mismatch with the expected and actual frame
+	}
+
+A recording taken at the server replays the client after Invert.
+
+The nexio-replay command prints and summarises recordings.
+*/
+package replay
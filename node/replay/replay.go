@@ -0,0 +1,233 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+	"github.com/georghagn/nexio/node/transport/middleware"
+)
+
+// Recorder is a middleware.Record writing to a file it owns.
+// Closing the connection closes the file.
+type Recorder struct {
+	*middleware.Record
+	file *os.File
+}
+
+// Record starts recording conn to path (created or truncated).
+func Record(conn transport.Connection, path string) (*Recorder, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Record: middleware.NewRecord(conn, f), file: f}, nil
+}
+
+func (r *Recorder) Close(reason string) error {
+	err := r.Record.Close(reason)
+	if ferr := r.file.Close(); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+// Load reads a recording.
+func Load(r io.Reader) ([]middleware.Frame, error) {
+	var frames []middleware.Frame
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var f middleware.Frame
+		if err := json.Unmarshal(sc.Bytes(), &f); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		frames = append(frames, f)
+	}
+	return frames, sc.Err()
+}
+
+// LoadFile reads a recording from path.
+func LoadFile(path string) ([]middleware.Frame, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// Invert swaps the directions, i.e. looks at the session from the peer.
+func Invert(frames []middleware.Frame) []middleware.Frame {
+	out := make([]middleware.Frame, len(frames))
+	for i, f := range frames {
+		if f.Dir == middleware.DirOut {
+			f.Dir = middleware.DirIn
+		} else {
+			f.Dir = middleware.DirOut
+		}
+		out[i] = f
+	}
+	return out
+}
+
+// Mismatch is returned by Run when the Node deviates from the recording.
+type Mismatch struct {
+	Index int    // index of the expected frame
+	Want  []byte // nil: no frame was expected
+	Got   []byte // nil: nothing arrived in time
+}
+
+func (m *Mismatch) Error() string {
+	switch {
+	case m.Got == nil:
+		return fmt.Sprintf("frame %d: expected %s, got nothing", m.Index, m.Want)
+	case m.Want == nil:
+		return fmt.Sprintf("unexpected frame after the recording: %s", m.Got)
+	}
+	return fmt.Sprintf("frame %d: expected %s, got %s", m.Index, m.Want, m.Got)
+}
+
+// Replayer plays the "out" frames of a recording into a connection and
+// checks that the "in" frames come back from the Node on the other end.
+type Replayer struct {
+	frames []middleware.Frame
+	local  *transport.MemConnection // handed to the Node
+	remote *transport.MemConnection
+
+	// Timeout is how long to wait for each expected frame (default 1s).
+	Timeout time.Duration
+	// RealTime keeps the recorded gaps between sent frames;
+	// by default frames are sent as fast as the Node answers.
+	RealTime bool
+	// Equal compares an expected and an actual frame. The default
+	// compares JSON semantically and ignores the "meta" member (trace ids).
+	Equal func(want, got []byte) bool
+}
+
+func NewReplayer(frames []middleware.Frame) *Replayer {
+	remote, local := transport.NewMemPair()
+	return &Replayer{
+		frames:  frames,
+		local:   local,
+		remote:  remote,
+		Timeout: time.Second,
+		Equal:   EqualFrames,
+	}
+}
+
+// Conn is the connection for the Node under test.
+func (r *Replayer) Conn() transport.Connection {
+	return r.local
+}
+
+// Run plays the recording. Consecutive expected frames may arrive in any
+// order, since a Node answers concurrent requests concurrently. After the
+// last frame Run checks briefly that the Node sends nothing else.
+func (r *Replayer) Run(ctx context.Context) error {
+	var last time.Time
+	for i := 0; i < len(r.frames); {
+		f := r.frames[i]
+		if f.Dir == middleware.DirOut {
+			if r.RealTime && !last.IsZero() {
+				if err := sleep(ctx, f.T.Sub(last)); err != nil {
+					return err
+				}
+			}
+			last = f.T
+			if err := r.remote.Send(ctx, f.Data()); err != nil {
+				return err
+			}
+			i++
+			continue
+		}
+
+		// Collect the run of expected frames.
+		j := i
+		for j < len(r.frames) && r.frames[j].Dir == middleware.DirIn {
+			j++
+		}
+		if err := r.expect(ctx, i, r.frames[i:j]); err != nil {
+			return err
+		}
+		i = j
+	}
+
+	// Nothing more should come.
+	qctx, cancel := context.WithTimeout(ctx, r.Timeout/10)
+	defer cancel()
+	if got, err := r.remote.Receive(qctx); err == nil {
+		return &Mismatch{Index: len(r.frames), Got: got}
+	}
+	return nil
+}
+
+func (r *Replayer) expect(ctx context.Context, index int, want []middleware.Frame) error {
+	open := append([]middleware.Frame(nil), want...)
+	for len(open) > 0 {
+		wctx, cancel := context.WithTimeout(ctx, r.Timeout)
+		got, err := r.remote.Receive(wctx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return &Mismatch{Index: index + len(want) - len(open), Want: open[0].Data()}
+		}
+
+		matched := -1
+		for k, f := range open {
+			if r.Equal(f.Data(), got) {
+				matched = k
+				break
+			}
+		}
+		if matched < 0 {
+			return &Mismatch{Index: index + len(want) - len(open), Want: open[0].Data(), Got: got}
+		}
+		open = append(open[:matched], open[matched+1:]...)
+	}
+	return nil
+}
+
+// EqualFrames compares JSON frames semantically, ignoring the top-level
+// "meta" member; binary frames must match exactly.
+func EqualFrames(want, got []byte) bool {
+	if transport.IsBinaryFrame(want) || transport.IsBinaryFrame(got) {
+		return bytes.Equal(want, got)
+	}
+	var w, g map[string]any
+	if json.Unmarshal(want, &w) != nil || json.Unmarshal(got, &g) != nil {
+		return bytes.Equal(want, got)
+	}
+	delete(w, "meta")
+	delete(g, "meta")
+	return reflect.DeepEqual(w, g)
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func registerPrice(node *rpc.Node, factor int) {
+	node.Register("price.quote", func(ctx context.Context, p json.RawMessage) (any, error) {
+		var qty int
+		if err := json.Unmarshal(p, &qty); err != nil {
+			return nil, err
+		}
+		return qty * factor, nil
+	})
+}
+
+// recordSession records a short client session against a server.
+func recordSession(t *testing.T, path string) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	rec, err := Record(clientConn, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := rpc.NewNode(serverConn, nil, "", nil)
+	client := rpc.NewNode(rec, nil, "", nil)
+	registerPrice(server, 3)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+
+	for _, qty := range []int{1, 5} {
+		if _, err := client.Call(ctx, "price.quote", qty); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = client.Notify(ctx, "price.seen", nil)
+	if _, err := client.Call(ctx, "price.unknown", nil); err == nil {
+		t.Fatal("expected method not found")
+	}
+	if err := rec.Close("done"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplay_AgainstLocalNode(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recordSession(t, path)
+
+	frames, err := LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(frames) != 7 {
+		t.Fatalf("expected 7 frames, got %d", len(frames))
+	}
+
+	run := func(factor int) error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		rp := NewReplayer(frames)
+		rp.Timeout = 200 * time.Millisecond
+		node := rpc.NewNode(rp.Conn(), nil, "", nil)
+		registerPrice(node, factor)
+		go node.Listen(ctx)
+		return rp.Run(ctx)
+	}
+
+	if err := run(3); err != nil {
+		t.Fatalf("replay of the same behaviour failed: %v", err)
+	}
+
+	err = run(4)
+	var m *Mismatch
+	if !errors.As(err, &m) || m.Index != 1 || m.Got == nil {
+		t.Fatalf("expected mismatch at frame 1, got %v", err)
+	}
+}
+
+func TestInvert(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.jsonl")
+	recordSession(t, path)
+	frames, _ := LoadFile(path)
+
+	// Seen from the server, the server replies are sent and the requests expected.
+	inv := Invert(frames)
+	if inv[0].Dir != "in" || inv[1].Dir != "out" || frames[0].Dir != "out" {
+		t.Fatalf("unexpected directions %s %s", inv[0].Dir, inv[1].Dir)
+	}
+}
@@ -0,0 +1,27 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package router relays calls through an intermediate (gateway) Node.
+
+A gateway attaches a Router to every Node it accepts. Peers announce
+themselves by name; calls addressed to "peer/method" are then forwarded
+to that peer, and results or errors are relayed back unchanged.
+Cancellation travels along with the call. A name belongs to the peer
+that announced it until its connection is lost; announcing a name
+another connected peer holds fails.
+
+Gateway:
+
+	r := router.NewRouter(logger)
+	r.Attach(node) // for every accepted connection
+
+Payment service (connected to the gateway):
+
+	router.Announce(ctx, node, "payment")
+
+Order service (connected to the gateway):
+
+	res, err := node.Call(ctx, router.Method("payment", "payment.process"), orderID)
+*/
+package router
@@ -0,0 +1,184 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// MethodAnnounce lets a peer register itself under a name at the gateway.
+const MethodAnnounce = "router.announce"
+
+// Separator splits the target peer from the method: "payment/payment.process".
+const Separator = "/"
+
+// ErrCodeNameTaken answers an announce for a name another connected
+// peer holds.
+const ErrCodeNameTaken = -32003
+
+// ErrNameTaken is returned by Add for a name another connected peer holds.
+var ErrNameTaken = errors.New("router: name taken")
+
+type announceParams struct {
+	Name string `json:"name"`
+}
+
+// Method builds the routed method name for peer.
+func Method(peer, method string) string {
+	return peer + Separator + method
+}
+
+// Router forwards calls addressed to "peer/method" to the named peer
+// and relays results and errors back to the caller. Every hop uses its
+// own Node.Call, so request ids never collide; a cancelled caller
+// cancels the relayed call via "rpc.cancel".
+type Router struct {
+	mu     sync.RWMutex
+	peers  map[string]*rpc.Node
+	hooked map[*rpc.Node]bool // nodes with a disconnect hook
+
+	Log transport.LogSink
+}
+
+func NewRouter(logger transport.LogSink) *Router {
+	r := &Router{
+		peers:  make(map[string]*rpc.Node),
+		hooked: make(map[*rpc.Node]bool),
+		Log:    &transport.SilentLogger{},
+	}
+	if logger != nil {
+		r.Log = logger
+	}
+	return r
+}
+
+// Add makes node reachable as name. It is removed again when its
+// connection is lost. A name held by another connected node is not
+// taken over (ErrNameTaken); Remove it first.
+func (r *Router) Add(name string, node *rpc.Node) error {
+	r.mu.Lock()
+	if other := r.peers[name]; other != nil && other != node && other.Connected() {
+		r.mu.Unlock()
+		return ErrNameTaken
+	}
+	r.peers[name] = node
+	hook := !r.hooked[node]
+	r.hooked[node] = true
+	r.mu.Unlock()
+
+	r.Log.With("peer", name).Info("Peer added to router")
+	if hook {
+		node.OnDisconnect(func(err error) { r.drop(node) })
+	}
+	return nil
+}
+
+// drop removes all names of node (connection lost).
+func (r *Router) drop(node *rpc.Node) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, n := range r.peers {
+		if n == node {
+			delete(r.peers, name)
+		}
+	}
+	delete(r.hooked, node)
+}
+
+// Remove drops the peer registered as name.
+func (r *Router) Remove(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.peers, name)
+}
+
+// Peers returns the names of all reachable peers.
+func (r *Router) Peers() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.peers))
+	for name := range r.peers {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Attach enables routing for requests arriving on node and lets its
+// peer announce itself with "router.announce".
+func (r *Router) Attach(node *rpc.Node) {
+	node.Register(MethodAnnounce, func(ctx context.Context, p json.RawMessage) (any, error) {
+		a, err := rpc.Bind[announceParams](p)
+		if err != nil {
+			return nil, err
+		}
+		if a.Name == "" {
+			return nil, rpc.NewRPCError(rpc.ErrCodeInvalidParams, "name is required")
+		}
+		if err := r.Add(a.Name, node); err != nil {
+			return nil, rpc.NewRPCError(ErrCodeNameTaken, a.Name)
+		}
+		return true, nil
+	})
+	node.SetFallback(r.relay)
+}
+
+// relay forwards one request. Errors of the target are returned as they
+// are (*rpc.RPCError), so the caller sees the original code and data.
+func (r *Router) relay(ctx context.Context, req rpc.Request) (any, error) {
+	peer, method, ok := strings.Cut(req.Method, Separator)
+	if !ok {
+		return nil, rpc.NewRPCError(rpc.ErrCodeMethodNotFound, req.Method)
+	}
+
+	r.mu.RLock()
+	target, found := r.peers[peer]
+	r.mu.RUnlock()
+	if !found {
+		return nil, rpc.NewRPCError(rpc.ErrCodeMethodNotFound, "unknown peer: "+peer)
+	}
+
+	params := req.Params
+	if len(params) == 0 {
+		params = json.RawMessage("null")
+	}
+
+	// The trace continues through ctx and attachments are streamed anew,
+	// everything else (e.g. the idempotency key) is passed on as is.
+	meta := make(map[string]string, len(req.Meta))
+	for k, v := range req.Meta {
+		switch k {
+		case rpc.MetaTraceparent, rpc.MetaTracestate, rpc.MetaAttachments:
+		default:
+			meta[k] = v
+		}
+	}
+
+	if req.IsNotification() {
+		return nil, target.NotifyWithMeta(ctx, method, params, meta)
+	}
+	res, err := target.CallWithMeta(ctx, method, params, meta, rpc.Attachments(ctx)...)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// Announce registers node under name at the gateway on the other end
+// and repeats the announcement after every reconnect.
+func Announce(ctx context.Context, node *rpc.Node, name string) error {
+	node.OnConnect(func(ctx context.Context) {
+		if _, err := node.Call(ctx, MethodAnnounce, announceParams{Name: name}); err != nil {
+			node.Log.With("error", err).Warn("Re-announce failed")
+		}
+	})
+	_, err := node.Call(ctx, MethodAnnounce, announceParams{Name: name})
+	return err
+}
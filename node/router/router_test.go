@@ -0,0 +1,184 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// mesh wires order <-> gateway <-> payment over memory connections.
+func mesh(t *testing.T, ctx context.Context) (order, payment *rpc.Node, r *Router) {
+	orderConn, gwOrderConn := transport.NewMemPair()
+	paymentConn, gwPaymentConn := transport.NewMemPair()
+
+	order = rpc.NewNode(orderConn, nil, "", nil)
+	payment = rpc.NewNode(paymentConn, nil, "", nil)
+	gwOrder := rpc.NewNode(gwOrderConn, nil, "", nil)
+	gwPayment := rpc.NewNode(gwPaymentConn, nil, "", nil)
+
+	r = NewRouter(nil)
+	r.Attach(gwOrder)
+	r.Attach(gwPayment)
+
+	for _, n := range []*rpc.Node{order, payment, gwOrder, gwPayment} {
+		go n.Listen(ctx)
+	}
+
+	if err := Announce(ctx, payment, "payment"); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	return order, payment, r
+}
+
+func TestRelayCall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	order, payment, _ := mesh(t, ctx)
+	payment.Register("payment.process", func(ctx context.Context, p json.RawMessage) (any, error) {
+		id, _ := rpc.Bind[string](p)
+		if id == "" {
+			return nil, rpc.NewRPCError(rpc.ErrCodeInvalidParams, "order id missing")
+		}
+		return "paid " + id, nil
+	})
+
+	res, err := order.Call(ctx, Method("payment", "payment.process"), "A-1")
+	if err != nil || string(res) != `"paid A-1"` {
+		t.Fatalf("Unexpected result %s (%v)", res, err)
+	}
+
+	_, err = order.Call(ctx, Method("payment", "payment.process"), "")
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.ErrCodeInvalidParams {
+		t.Errorf("Expected relayed invalid params error, got %v", err)
+	}
+
+	_, err = order.Call(ctx, Method("nobody", "x"), nil)
+	if !errors.As(err, &rpcErr) || rpcErr.Code != rpc.ErrCodeMethodNotFound {
+		t.Errorf("Expected method not found for unknown peer, got %v", err)
+	}
+}
+
+func TestRelayMeta(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	order, payment, _ := mesh(t, ctx)
+	type seen struct {
+		traceID, key, blob string
+	}
+	got := make(chan seen, 1)
+	payment.Register("payment.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		var s seen
+		tc, _ := rpc.TraceFromContext(ctx)
+		s.traceID = tc.TraceID
+		req, _ := rpc.RequestFromContext(ctx)
+		s.key = req.Meta[rpc.MetaIdempotencyKey]
+		if files := rpc.Attachments(ctx); len(files) == 1 {
+			data, _ := io.ReadAll(files[0])
+			s.blob = string(data)
+		}
+		got <- s
+		return true, nil
+	})
+
+	root := rpc.NewTraceContext()
+	cctx := rpc.WithIdempotencyKey(rpc.ContextWithTrace(ctx, root), "key-1")
+	if _, err := order.CallWithAttachments(cctx, Method("payment", "payment.upload"), nil, strings.NewReader("receipt")); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	s := <-got
+	if s.traceID != root.TraceID || s.key != "key-1" || s.blob != "receipt" {
+		t.Errorf("Expected trace, idempotency key and attachment to be relayed, got %+v", s)
+	}
+}
+
+func TestRelayCancellation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	order, payment, _ := mesh(t, ctx)
+
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	payment.Register("payment.slow", func(ctx context.Context, p json.RawMessage) (any, error) {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+
+	callCtx, callCancel := context.WithCancel(ctx)
+	go func() {
+		<-started
+		callCancel()
+	}()
+	order.Call(callCtx, Method("payment", "payment.slow"), nil)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Cancellation was not propagated to the target peer")
+	}
+}
+
+func TestAnnounceNameTaken(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	order, payment, r := mesh(t, ctx)
+	payment.Register("payment.process", func(ctx context.Context, p json.RawMessage) (any, error) {
+		return "paid", nil
+	})
+
+	thiefConn, gwThiefConn := transport.NewMemPair()
+	thief := rpc.NewNode(thiefConn, nil, "", nil)
+	gwThief := rpc.NewNode(gwThiefConn, nil, "", nil)
+	r.Attach(gwThief)
+	go thief.Listen(ctx)
+	go gwThief.Listen(ctx)
+
+	_, err := thief.Call(ctx, MethodAnnounce, announceParams{Name: "payment"})
+	var rpcErr *rpc.RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != ErrCodeNameTaken {
+		t.Fatalf("Expected name taken, got %v", err)
+	}
+	if res, err := order.Call(ctx, Method("payment", "payment.process"), nil); err != nil || string(res) != `"paid"` {
+		t.Fatalf("Expected the owner to keep its name, got %s (%v)", res, err)
+	}
+
+	// The owner may announce again; one disconnect hook per node.
+	for range 3 {
+		if _, err := payment.Call(ctx, MethodAnnounce, announceParams{Name: "payment"}); err != nil {
+			t.Fatalf("Re-announce failed: %v", err)
+		}
+	}
+	if _, err := thief.Call(ctx, MethodAnnounce, announceParams{Name: "thief"}); err != nil {
+		t.Fatalf("Announce failed: %v", err)
+	}
+	r.mu.RLock()
+	hooked := len(r.hooked)
+	r.mu.RUnlock()
+	if hooked != 2 {
+		t.Fatalf("Expected 2 hooked nodes, got %d", hooked)
+	}
+
+	// A lost connection frees the names.
+	thiefConn.Close("gone")
+	deadline := time.Now().Add(time.Second)
+	for len(r.Peers()) != 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected only payment left, got %v", r.Peers())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
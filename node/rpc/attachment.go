@@ -0,0 +1,408 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// ChunkSize is the payload size of one attachment frame. It stays well
+// below the default websocket read limit of 32 KiB.
+const ChunkSize = 16 * 1024
+
+// MetaAttachments lists the stream ids of a request's attachments.
+const MetaAttachments = "attachments"
+
+// ErrAttachmentAborted is returned by an attachment reader when the
+// sender cancelled the transfer or the connection was lost.
+var ErrAttachmentAborted = errors.New("attachment aborted")
+
+// ErrAttachmentTooLarge is returned by an attachment reader when the
+// stream was aborted because its data exceeded a buffer limit.
+var ErrAttachmentTooLarge = errors.New("attachment buffer limit exceeded")
+
+// ErrTooManyAttachments is returned by an attachment reader when the
+// request announced more streams than the Node keeps open at once.
+var ErrTooManyAttachments = errors.New("too many open attachment streams")
+
+// Default limits for incoming attachments, see SetAttachmentLimits and
+// SetMaxAttachmentStreams.
+const (
+	DefaultStreamBuffer = 8 << 20
+	DefaultNodeBuffer   = 64 << 20
+	DefaultMaxStreams   = 256
+)
+
+// Binary frame layout: marker | kind | stream id (uint64 BE) | payload
+const (
+	frameChunk     byte = 0x01
+	chunkHeaderLen      = 10
+
+	chunkData  byte = 0
+	chunkEnd   byte = 1
+	chunkAbort byte = 2
+)
+
+type attachmentsKey struct{}
+
+// Attachments returns the attachment readers of the current request in
+// the order the caller passed them. The data is only available while the
+// handler runs.
+func Attachments(ctx context.Context) []io.Reader {
+	readers, _ := ctx.Value(attachmentsKey{}).([]io.Reader)
+	return readers
+}
+
+// CallWithAttachments is Call plus binary blobs. Each reader is streamed
+// in chunks after the request; chunks of concurrent calls are interleaved
+// fairly. If ctx ends or the call returns before a reader is drained,
+// the transfer is aborted.
+func (node *Node) CallWithAttachments(ctx context.Context, method string, params any, attachments ...io.Reader) (json.RawMessage, error) {
+	return node.CallWithMeta(ctx, method, params, nil, attachments...)
+}
+
+// CallWithMeta is CallWithAttachments plus extra request meta, e.g. the
+// idempotency key of a request that is relayed. The trace context is
+// still derived from ctx and the attachment list from attachments.
+func (node *Node) CallWithMeta(ctx context.Context, method string, params any, meta map[string]string, attachments ...io.Reader) (json.RawMessage, error) {
+	ids := make([]string, len(attachments))
+	streams := make([]uint64, len(attachments))
+	node.streamMu.Lock()
+	for i := range attachments {
+		node.nextStream++
+		streams[i] = node.nextStream
+		ids[i] = strconv.FormatUint(node.nextStream, 10)
+	}
+	node.streamMu.Unlock()
+
+	// Streams still running when the call returns are aborted.
+	sctx, stop := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		stop()
+		wg.Wait()
+	}()
+
+	m := make(map[string]string, len(meta)+1)
+	for k, v := range meta {
+		m[k] = v
+	}
+	delete(m, MetaAttachments)
+	if len(ids) > 0 {
+		m[MetaAttachments] = strings.Join(ids, ",")
+	}
+
+	return node.call(ctx, method, params, callOptions{
+		meta: m,
+		afterSend: func(conn transport.Connection) {
+			for i, r := range attachments {
+				wg.Add(1)
+				go func(id uint64, r io.Reader) {
+					defer wg.Done()
+					node.streamOut(sctx, conn, id, r)
+				}(streams[i], r)
+			}
+		},
+	})
+}
+
+// streamOut sends r as chunk frames. The fair lock hands out turns in
+// FIFO order, so concurrent streams take turns chunk by chunk.
+func (node *Node) streamOut(ctx context.Context, conn transport.Connection, id uint64, r io.Reader) {
+	buf := make([]byte, ChunkSize)
+	for ctx.Err() == nil {
+		n, err := r.Read(buf)
+		if n > 0 && ctx.Err() == nil {
+			node.sendLock.Lock()
+			sendErr := conn.Send(ctx, chunkFrame(chunkData, id, buf[:n]))
+			node.sendLock.Unlock()
+			if sendErr != nil {
+				break
+			}
+		}
+		if err == io.EOF && ctx.Err() == nil {
+			node.sendLock.Lock()
+			_ = conn.Send(ctx, chunkFrame(chunkEnd, id, nil))
+			node.sendLock.Unlock()
+			return
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	// ctx is probably done, the abort gets a short-lived one.
+	actx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = conn.Send(actx, chunkFrame(chunkAbort, id, nil))
+}
+
+// SetAttachmentLimits caps the incoming attachment data the Node buffers
+// for handlers that read slower than it arrives: per stream and for all
+// streams together. A stream that would exceed a limit is aborted and
+// its reader fails with ErrAttachmentTooLarge. 0 disables a limit.
+func (node *Node) SetAttachmentLimits(perStream, total int64) {
+	node.streamMu.Lock()
+	defer node.streamMu.Unlock()
+	node.streamLimit = perStream
+	node.bufferLimit = total
+}
+
+// SetMaxAttachmentStreams caps the incoming attachment streams open at
+// once on the connection. Further streams are not accepted and their
+// readers fail with ErrTooManyAttachments. 0 disables the limit.
+func (node *Node) SetMaxAttachmentStreams(n int) {
+	node.streamMu.Lock()
+	defer node.streamMu.Unlock()
+	node.streamMax = n
+}
+
+func chunkFrame(kind byte, id uint64, payload []byte) []byte {
+	frame := make([]byte, chunkHeaderLen+len(payload))
+	frame[0] = frameChunk
+	frame[1] = kind
+	binary.BigEndian.PutUint64(frame[2:chunkHeaderLen], id)
+	copy(frame[chunkHeaderLen:], payload)
+	return frame
+}
+
+// handleChunk buffers an incoming chunk for its stream. It runs in the
+// Listen loop, so chunks keep their order.
+func (node *Node) handleChunk(data []byte) {
+	if len(data) < chunkHeaderLen || data[0] != frameChunk {
+		node.Log.Warn("Unknown binary frame dropped")
+		return
+	}
+	kind := data[1]
+	id := binary.BigEndian.Uint64(data[2:chunkHeaderLen])
+
+	node.streamMu.Lock()
+	s, ok := node.streams[id]
+	node.streamMu.Unlock()
+	if !ok {
+		node.Log.With("stream", id).Debug("Chunk of unknown stream dropped")
+		return
+	}
+
+	switch kind {
+	case chunkData:
+		if !s.write(data[chunkHeaderLen:]) {
+			node.Log.With("stream", id).Warn("Attachment exceeds buffer limit, aborted")
+		}
+	case chunkEnd:
+		s.close(io.EOF)
+	default:
+		s.close(ErrAttachmentAborted)
+	}
+}
+
+// announceStreams registers the attachment streams of a request frame.
+// It runs in the Listen loop before the request is dispatched, so the
+// streams exist when their first chunk arrives. Only frames that
+// handleIncoming dispatches to withAttachments are considered, which
+// removes the streams again when the request is done.
+func (node *Node) announceStreams(data []byte) {
+	if !bytes.Contains(data, []byte(`"`+MetaAttachments+`"`)) || !strings.Contains(string(data), `"method"`) {
+		return
+	}
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil || req.Method == MethodCancel {
+		return
+	}
+
+	node.streamMu.Lock()
+	defer node.streamMu.Unlock()
+	for _, id := range streamIDs(req.Meta[MetaAttachments]) {
+		if _, ok := node.streams[id]; ok {
+			continue
+		}
+		if node.streamMax > 0 && len(node.streams) >= node.streamMax {
+			node.Log.With("stream", id).Warn("Too many open attachment streams, stream refused")
+			continue
+		}
+		s := &inStream{limit: node.streamLimit, total: &node.buffered, totalLimit: node.bufferLimit}
+		s.cond = sync.NewCond(&s.mu)
+		node.streams[id] = s
+	}
+}
+
+func streamIDs(list string) []uint64 {
+	var ids []uint64
+	for _, f := range strings.Split(list, ",") {
+		if id, err := strconv.ParseUint(f, 10, 64); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// withAttachments puts the readers announced in req.Meta into ctx and
+// returns a release func to call when the handler is done.
+func (node *Node) withAttachments(ctx context.Context, req Request) (context.Context, func()) {
+	list := req.Meta[MetaAttachments]
+	if list == "" {
+		return ctx, func() {}
+	}
+
+	ids := streamIDs(list)
+	streams := make([]*inStream, len(ids))
+	readers := make([]io.Reader, len(ids))
+	node.streamMu.Lock()
+	for i, id := range ids {
+		s, ok := node.streams[id]
+		if !ok {
+			// Refused by announceStreams (or lost with the connection).
+			s = &inStream{err: ErrTooManyAttachments, total: &node.buffered}
+			s.cond = sync.NewCond(&s.mu)
+		}
+		streams[i], readers[i] = s, s
+	}
+	node.streamMu.Unlock()
+
+	return context.WithValue(ctx, attachmentsKey{}, readers), func() {
+		node.streamMu.Lock()
+		defer node.streamMu.Unlock()
+		for i, id := range ids {
+			// Late chunks find no stream and are dropped.
+			streams[i].discard()
+			if node.streams[id] == streams[i] {
+				delete(node.streams, id)
+			}
+		}
+	}
+}
+
+// abortStreams fails all incoming transfers (connection lost).
+func (node *Node) abortStreams() {
+	node.streamMu.Lock()
+	defer node.streamMu.Unlock()
+	for id, s := range node.streams {
+		s.close(ErrAttachmentAborted)
+		s.discard()
+		delete(node.streams, id)
+	}
+}
+
+// inStream is the receiving side of an attachment. It buffers chunks so
+// that a slow handler never blocks the Listen loop, up to its limits.
+type inStream struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	chunks   [][]byte
+	size     int64 // buffered bytes
+	err      error // io.EOF or ErrAttachmentAborted once finished
+	dropping bool
+
+	limit      int64
+	total      *atomic.Int64 // buffered bytes of all streams of the Node
+	totalLimit int64
+}
+
+// write buffers p. It reports false if the stream had to be aborted
+// because p exceeds a limit.
+func (s *inStream) write(p []byte) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dropping || s.err != nil {
+		return true
+	}
+	n := int64(len(p))
+	if (s.limit > 0 && s.size+n > s.limit) || (s.totalLimit > 0 && s.total.Load()+n > s.totalLimit) {
+		s.err = ErrAttachmentTooLarge
+		s.dropLocked()
+		s.cond.Broadcast()
+		return false
+	}
+	s.chunks = append(s.chunks, p)
+	s.size += n
+	s.total.Add(n)
+	s.cond.Broadcast()
+	return true
+}
+
+func (s *inStream) close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err == nil {
+		s.err = err
+	}
+	s.cond.Broadcast()
+}
+
+func (s *inStream) discard() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropLocked()
+}
+
+func (s *inStream) dropLocked() {
+	s.dropping = true
+	s.chunks = nil
+	s.total.Add(-s.size)
+	s.size = 0
+}
+
+func (s *inStream) Read(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.chunks) == 0 && s.err == nil {
+		s.cond.Wait()
+	}
+	if len(s.chunks) == 0 {
+		return 0, s.err
+	}
+	n := copy(p, s.chunks[0])
+	s.size -= int64(n)
+	s.total.Add(-int64(n))
+	if n == len(s.chunks[0]) {
+		s.chunks = s.chunks[1:]
+	} else {
+		s.chunks[0] = s.chunks[0][n:]
+	}
+	return n, nil
+}
+
+// fairLock grants the lock in FIFO order (sync.Mutex does not).
+type fairLock struct {
+	mu      sync.Mutex
+	busy    bool
+	waiters []chan struct{}
+}
+
+func (f *fairLock) Lock() {
+	f.mu.Lock()
+	if !f.busy {
+		f.busy = true
+		f.mu.Unlock()
+		return
+	}
+	ch := make(chan struct{})
+	f.waiters = append(f.waiters, ch)
+	f.mu.Unlock()
+	<-ch
+}
+
+func (f *fairLock) Unlock() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.waiters) == 0 {
+		f.busy = false
+		return
+	}
+	next := f.waiters[0]
+	f.waiters = f.waiters[1:]
+	close(next) // ownership passes on, busy stays true
+}
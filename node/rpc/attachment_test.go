@@ -0,0 +1,257 @@
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+func TestCallWithAttachments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	serverNode.Register("file.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		var sizes []int
+		for _, r := range Attachments(ctx) {
+			data, err := io.ReadAll(r)
+			if err != nil {
+				return nil, err
+			}
+			sizes = append(sizes, len(data))
+		}
+		return sizes, nil
+	})
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	big := make([]byte, 5*ChunkSize+123)
+	rand.Read(big)
+
+	// Two concurrent uploads share the connection.
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			res, err := clientNode.CallWithAttachments(ctx, "file.upload", nil,
+				bytes.NewReader(big), bytes.NewReader([]byte("small")))
+			if err != nil {
+				t.Errorf("Call failed: %v", err)
+				return
+			}
+			if string(res) != "[82043,5]" {
+				t.Errorf("Unexpected sizes %s", res)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// blockingReader delivers one chunk and then blocks until released.
+type blockingReader struct {
+	sent    bool
+	release chan struct{}
+}
+
+func (b *blockingReader) Read(p []byte) (int, error) {
+	if !b.sent {
+		b.sent = true
+		return copy(p, "first"), nil
+	}
+	<-b.release
+	return 0, io.EOF
+}
+
+func TestAttachmentAbortOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	readErr := make(chan error, 1)
+	serverNode.Register("file.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		_, err := io.ReadAll(Attachments(ctx)[0])
+		readErr <- err
+		return nil, err
+	})
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	r := &blockingReader{release: make(chan struct{})}
+	callCtx, callCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer callCancel()
+	go func() {
+		<-callCtx.Done()
+		close(r.release)
+	}()
+	clientNode.CallWithAttachments(callCtx, "file.upload", nil, r)
+
+	select {
+	case err := <-readErr:
+		if !errors.Is(err, ErrAttachmentAborted) {
+			t.Errorf("Expected ErrAttachmentAborted, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Handler reader was not aborted")
+	}
+}
+
+// settled waits until all chunks of s have arrived or it was aborted.
+func settled(s *inStream) {
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		s.mu.Lock()
+		err := s.err
+		s.mu.Unlock()
+		if err != nil {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestAttachmentLimits(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	// A slow handler: it reads only after all data was buffered.
+	serverNode.Register("file.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		var results []string
+		for _, r := range Attachments(ctx) {
+			settled(r.(*inStream))
+		}
+		for _, r := range Attachments(ctx) {
+			if _, err := io.ReadAll(r); err != nil {
+				results = append(results, err.Error())
+			} else {
+				results = append(results, "ok")
+			}
+		}
+		return results, nil
+	})
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	twoChunks := make([]byte, 2*ChunkSize)
+
+	// Per stream: a 6 chunk attachment exceeds 4 chunks.
+	serverNode.SetAttachmentLimits(4*ChunkSize, 0)
+	res, err := clientNode.CallWithAttachments(ctx, "file.upload", nil,
+		bytes.NewReader(make([]byte, 6*ChunkSize)), bytes.NewReader(twoChunks))
+	if err != nil || string(res) != `["attachment buffer limit exceeded","ok"]` {
+		t.Errorf("Per stream: unexpected %s (%v)", res, err)
+	}
+
+	// Per Node: two streams of 2 chunks do not fit into 3 chunks.
+	serverNode.SetAttachmentLimits(0, 3*ChunkSize)
+	res, err = clientNode.CallWithAttachments(ctx, "file.upload", nil,
+		bytes.NewReader(twoChunks), bytes.NewReader(twoChunks))
+	var results []string
+	json.Unmarshal(res, &results)
+	if err != nil || len(results) != 2 || (results[0] == "ok") == (results[1] == "ok") {
+		t.Errorf("Per node: expected exactly one aborted stream, got %s (%v)", res, err)
+	}
+
+	if n := serverNode.buffered.Load(); n != 0 {
+		t.Errorf("Expected all buffers to be released, %d bytes left", n)
+	}
+}
+
+func TestAttachmentUnknownStream(t *testing.T) {
+	node := NewNode(nil, nil, "", nil)
+	node.handleChunk(chunkFrame(chunkData, 42, []byte("orphan")))
+	node.handleChunk(chunkFrame(chunkEnd, 43, nil))
+
+	node.streamMu.Lock()
+	defer node.streamMu.Unlock()
+	if len(node.streams) != 0 || node.buffered.Load() != 0 {
+		t.Errorf("Expected chunks of unknown streams to be dropped, got %d streams", len(node.streams))
+	}
+}
+
+func TestAttachmentStreamsReleased(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peer, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	serverNode.Register("file.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		return len(Attachments(ctx)), nil
+	})
+	go serverNode.Listen(ctx)
+
+	// Announced streams that never end go with their request.
+	for id := 1; id <= 3; id++ {
+		req := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"file.upload","meta":{"attachments":"%d,%d"}}`, id, 10*id, 10*id+1)
+		if err := peer.Send(ctx, []byte(req)); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := peer.Receive(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// rpc.cancel never reaches a handler, so it announces nothing.
+	peer.Send(ctx, []byte(`{"jsonrpc":"2.0","method":"rpc.cancel","params":{"id":1},"meta":{"attachments":"99"}}`))
+	peer.Send(ctx, []byte(`{"jsonrpc":"2.0","id":9,"method":"file.upload"}`))
+	peer.Receive(ctx)
+
+	// Streams are released right after the response is sent.
+	waitFor(t, "no open streams", func() bool {
+		serverNode.streamMu.Lock()
+		defer serverNode.streamMu.Unlock()
+		return len(serverNode.streams) == 0
+	})
+}
+
+func TestAttachmentMaxStreams(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+	serverNode.SetMaxAttachmentStreams(2)
+
+	serverNode.Register("file.upload", func(ctx context.Context, p json.RawMessage) (any, error) {
+		var results []string
+		for _, r := range Attachments(ctx) {
+			if _, err := io.ReadAll(r); err != nil {
+				results = append(results, err.Error())
+			} else {
+				results = append(results, "ok")
+			}
+		}
+		return results, nil
+	})
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	res, err := clientNode.CallWithAttachments(ctx, "file.upload", nil,
+		strings.NewReader("a"), strings.NewReader("b"), strings.NewReader("c"))
+	if err != nil || string(res) != `["ok","ok","too many open attachment streams"]` {
+		t.Errorf("Unexpected %s (%v)", res, err)
+	}
+}
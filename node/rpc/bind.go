@@ -0,0 +1,309 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// ParamError describes a single params mismatch. A list of them is sent
+// in the Data field of an ErrCodeInvalidParams error.
+type ParamError struct {
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Bind decodes raw params into T.
+//
+// JSON-RPC 2.0 allows params by-name (object) and by-position (array):
+//   - object params are mapped onto struct fields by their json name
+//     (as encoding/json does, unknown members are ignored),
+//   - array params are mapped onto struct fields in declared order,
+//     or by the `rpc:"<index>"` tag if fields carry one,
+//   - a single-element array is unwrapped for non-list targets.
+//
+// Empty params yield the zero value of T. Mismatches are reported as
+// ErrCodeInvalidParams with a []ParamError in Data.
+func Bind[T any](params json.RawMessage) (T, error) {
+	var v T
+	err := bindInto(params, reflect.ValueOf(&v).Elem())
+	return v, err
+}
+
+// Typed adapts a typed function to a HandlerFunc, binding params with Bind.
+//
+//	node.Register("payment.process", rpc.Typed(svc.Process))
+func Typed[P, R any](fn func(ctx context.Context, p P) (R, error)) HandlerFunc {
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		p, err := Bind[P](params)
+		if err != nil {
+			return nil, err
+		}
+		return fn(ctx, p)
+	}
+}
+
+// bindInto decodes params into the settable value dst.
+func bindInto(params json.RawMessage, dst reflect.Value) error {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+
+	target := dst
+	for target.Kind() == reflect.Pointer {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		target = target.Elem()
+	}
+
+	// Types with their own decoding (e.g. time.Time) are left to encoding/json.
+	custom := target.Addr().Type().Implements(unmarshalerType)
+
+	var errs []ParamError
+	switch {
+	case custom:
+		if err := json.Unmarshal(params, dst.Addr().Interface()); err != nil {
+			errs = []ParamError{{Field: "params", Reason: reason(err)}}
+		}
+	case params[0] == '[' && target.Kind() == reflect.Struct:
+		errs = bindPositional(params, target)
+	case params[0] == '{' && target.Kind() == reflect.Struct:
+		errs = bindNamed(params, target)
+	case params[0] == '[' && !isList(target):
+		var elems []json.RawMessage
+		if err := json.Unmarshal(params, &elems); err != nil || len(elems) != 1 {
+			errs = []ParamError{{Field: "params", Reason: fmt.Sprintf("expected a single value for %s", target.Type())}}
+			break
+		}
+		return bindInto(elems[0], dst)
+	default:
+		if err := json.Unmarshal(params, dst.Addr().Interface()); err != nil {
+			errs = []ParamError{{Field: "params", Reason: reason(err)}}
+		}
+	}
+
+	if len(errs) > 0 {
+		return NewRPCError(ErrCodeInvalidParams, errs)
+	}
+	return nil
+}
+
+func bindPositional(params json.RawMessage, target reflect.Value) []ParamError {
+	var elems []json.RawMessage
+	if err := json.Unmarshal(params, &elems); err != nil {
+		return []ParamError{{Field: "params", Reason: reason(err)}}
+	}
+
+	fields := positionalFields(target.Type())
+	if len(elems) > len(fields) {
+		return []ParamError{{Field: "params", Reason: fmt.Sprintf("too many params: got %d, want at most %d", len(elems), len(fields))}}
+	}
+
+	var errs []ParamError
+	for i, raw := range elems {
+		f := fields[i]
+		if err := json.Unmarshal(raw, fieldByIndex(target, f.index).Addr().Interface()); err != nil {
+			errs = append(errs, ParamError{Field: f.name, Reason: fmt.Sprintf("position %d: %s", i, reason(err))})
+		}
+	}
+	return errs
+}
+
+// bindNamed assigns the members in document order, matching names the
+// way encoding/json does: exactly, else case-insensitively to the first
+// field in declared order. Members without a field are ignored, also
+// like encoding/json, so peers may send fields a handler does not know.
+func bindNamed(params json.RawMessage, target reflect.Value) []ParamError {
+	dec := json.NewDecoder(bytes.NewReader(params))
+	if _, err := dec.Token(); err != nil {
+		return []ParamError{{Field: "params", Reason: reason(err)}}
+	}
+
+	fields := positionalFields(target.Type())
+	declared := slices.Clone(fields)
+	slices.SortStableFunc(declared, func(a, b paramField) int { return slices.Compare(a.index, b.index) })
+	exact := make(map[string]paramField, len(fields))
+	for _, f := range fields {
+		exact[f.name] = f
+	}
+
+	var errs []ParamError
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return []ParamError{{Field: "params", Reason: reason(err)}}
+		}
+		name, _ := tok.(string)
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return []ParamError{{Field: "params", Reason: reason(err)}}
+		}
+
+		f, ok := exact[name]
+		if !ok {
+			for _, d := range declared {
+				if strings.EqualFold(d.name, name) {
+					f, ok = d, true
+					break
+				}
+			}
+		}
+		if !ok {
+			continue
+		}
+		if err := json.Unmarshal(raw, fieldByIndex(target, f.index).Addr().Interface()); err != nil {
+			errs = append(errs, ParamError{Field: f.name, Reason: reason(err)})
+		}
+	}
+	return errs
+}
+
+type paramField struct {
+	name   string
+	index  []int
+	pos    int
+	tagged bool // name comes from a json tag
+}
+
+// positionalFields lists the exported, json-visible fields of t in
+// positional order: by `rpc:"<index>"` tag if present, else declared order.
+// Fields of embedded structs are promoted following the encoding/json
+// rules: the shallowest field wins, equally deep conflicts are dropped
+// unless exactly one of them is tagged.
+func positionalFields(t reflect.Type) []paramField {
+	var all []paramField
+	tagged := collectFields(t, nil, map[reflect.Type]bool{}, &all)
+
+	byName := make(map[string][]paramField)
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	var fields []paramField
+	for _, f := range all {
+		if dominant(f, byName[f.name]) {
+			fields = append(fields, f)
+		}
+	}
+	if tagged {
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].pos < fields[j].pos })
+	}
+	return fields
+}
+
+// collectFields appends the fields of t (and of its embedded structs)
+// in declared order and reports whether any carries an rpc position tag.
+func collectFields(t reflect.Type, index []int, visiting map[reflect.Type]bool, out *[]paramField) bool {
+	if visiting[t] {
+		return false
+	}
+	visiting[t] = true
+	defer delete(visiting, t)
+
+	tagged := false
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name, jsonTagged := sf.Name, false
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			n, _, _ := strings.Cut(tag, ",")
+			if n == "-" {
+				continue
+			}
+			if n != "" {
+				name, jsonTagged = n, true
+			}
+		}
+		idx := append(append([]int(nil), index...), i)
+
+		if sf.Anonymous && !jsonTagged {
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				if !sf.IsExported() {
+					continue // cannot be allocated, encoding/json ignores it too
+				}
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				if collectFields(ft, idx, visiting, out) {
+					tagged = true
+				}
+				continue
+			}
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		pos := len(*out)
+		if tag, ok := sf.Tag.Lookup("rpc"); ok {
+			if p, err := strconv.Atoi(tag); err == nil {
+				pos = p
+				tagged = true
+			}
+		}
+		*out = append(*out, paramField{name: name, index: idx, pos: pos, tagged: jsonTagged})
+	}
+	return tagged
+}
+
+// dominant reports whether f survives among the fields sharing its name.
+func dominant(f paramField, same []paramField) bool {
+	equal, tagged := 0, 0
+	for _, o := range same {
+		if len(o.index) < len(f.index) {
+			return false
+		}
+		if len(o.index) == len(f.index) {
+			equal++
+			if o.tagged {
+				tagged++
+			}
+		}
+	}
+	return equal == 1 || (f.tagged && tagged == 1)
+}
+
+// fieldByIndex is reflect.Value.FieldByIndex, allocating nil embedded
+// struct pointers on the way.
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+func isList(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array, reflect.Interface:
+		return true
+	}
+	return false
+}
+
+// reason shortens json errors to the part a peer can act on.
+func reason(err error) string {
+	var te *json.UnmarshalTypeError
+	if errors.As(err, &te) {
+		return fmt.Sprintf("expected %s, got %s", te.Type, te.Value)
+	}
+	return err.Error()
+}
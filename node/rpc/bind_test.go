@@ -0,0 +1,133 @@
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type transferParams struct {
+	From   string `json:"from"`
+	To     string `json:"to"`
+	Amount int    `json:"amount"`
+}
+
+type taggedParams struct {
+	Amount int    `json:"amount" rpc:"1"`
+	Target string `json:"target" rpc:"0"`
+}
+
+func TestBindObjectAndArray(t *testing.T) {
+	want := transferParams{From: "a", To: "b", Amount: 5}
+
+	byName, err := Bind[transferParams](json.RawMessage(`{"from":"a","to":"b","amount":5}`))
+	if err != nil || byName != want {
+		t.Errorf("Object: expected %+v, got %+v (%v)", want, byName, err)
+	}
+
+	byPos, err := Bind[transferParams](json.RawMessage(`["a","b",5]`))
+	if err != nil || byPos != want {
+		t.Errorf("Array: expected %+v, got %+v (%v)", want, byPos, err)
+	}
+
+	tagged, err := Bind[taggedParams](json.RawMessage(`["acc-1",7]`))
+	if err != nil || tagged.Target != "acc-1" || tagged.Amount != 7 {
+		t.Errorf("Tagged: unexpected %+v (%v)", tagged, err)
+	}
+
+	single, err := Bind[string](json.RawMessage(`["Order_#42"]`))
+	if err != nil || single != "Order_#42" {
+		t.Errorf("Single: unexpected %q (%v)", single, err)
+	}
+
+	when, err := Bind[time.Time](json.RawMessage(`"2026-01-02T03:04:05Z"`))
+	if err != nil || when.Year() != 2026 {
+		t.Errorf("Time: unexpected %v (%v)", when, err)
+	}
+}
+
+type baseParams struct {
+	ID    int    `json:"id"`
+	Trace string `json:"trace"`
+}
+
+type Audit struct {
+	By string `json:"by"`
+}
+
+type embeddedParams struct {
+	baseParams
+	*Audit
+	Name  string `json:"name"`
+	Trace string `json:"trace"` // shadows baseParams.Trace
+}
+
+func TestBindEmbedded(t *testing.T) {
+	byName, err := Bind[embeddedParams](json.RawMessage(`{"id":7,"by":"ops","name":"x","trace":"t"}`))
+	if err != nil || byName.ID != 7 || byName.Audit == nil || byName.By != "ops" || byName.Name != "x" {
+		t.Fatalf("Object: unexpected %+v (%v)", byName, err)
+	}
+	if byName.Trace != "t" || byName.baseParams.Trace != "" {
+		t.Errorf("Expected the outer trace field to win, got %+v", byName)
+	}
+
+	// Promoted fields take their embedded position: id, by, name, trace.
+	byPos, err := Bind[embeddedParams](json.RawMessage(`[7,"ops","x","t"]`))
+	if err != nil || byPos.ID != 7 || byPos.By != "ops" || byPos.Name != "x" || byPos.Trace != "t" {
+		t.Errorf("Array: unexpected %+v (%v)", byPos, err)
+	}
+
+	var std embeddedParams
+	json.Unmarshal([]byte(`{"id":7,"by":"ops","name":"x","trace":"t"}`), &std)
+	if std.ID != byName.ID || std.By != byName.By || std.Trace != byName.Trace {
+		t.Errorf("Expected the encoding/json result %+v, got %+v", std, byName)
+	}
+}
+
+func TestBindFieldErrors(t *testing.T) {
+	_, err := Bind[transferParams](json.RawMessage(`["a",1,"x"]`))
+
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != ErrCodeInvalidParams {
+		t.Fatalf("Expected invalid params error, got %v", err)
+	}
+
+	var details []ParamError
+	json.Unmarshal(rpcErr.Data, &details)
+	if len(details) != 2 || details[0].Field != "to" || details[1].Field != "amount" {
+		t.Errorf("Expected errors for to and amount, got %+v", details)
+	}
+
+	if _, err := Bind[transferParams](json.RawMessage(`["a","b",1,2]`)); err == nil {
+		t.Error("Expected error for too many params")
+	}
+}
+
+type foldParams struct {
+	Lower string `json:"ab"`
+	Upper string `json:"AB"`
+	Other int    `json:"other"`
+}
+
+func TestBindNamedMatchesEncodingJSON(t *testing.T) {
+	for _, params := range []string{
+		`{"Ab":"x"}`,
+		`{"aB":"x","AB":"y"}`,
+		`{"OTHER":1,"other":2}`,
+		`{"other":2,"OTHER":1}`,
+		`{"ab":"x","unknown":true}`,
+	} {
+		var want foldParams
+		if err := json.Unmarshal([]byte(params), &want); err != nil {
+			t.Fatal(err)
+		}
+		// Repeat: a map-ordered lookup would differ between runs.
+		for range 20 {
+			got, err := Bind[foldParams](json.RawMessage(params))
+			if err != nil || got != want {
+				t.Fatalf("%s: expected %+v, got %+v (%v)", params, want, got, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// callbackPrefix marks ephemeral callback methods. They are not listed
+// by Methods/"rpc.discover".
+const callbackPrefix = "rpc.callback."
+
+// Callback is a handle to an ephemeral method on the Node that created
+// it. It travels inside params as {"$callback": "<method>"}:
+//
+//	progress := node.NewCallback(func(ctx context.Context, p json.RawMessage) (any, error) {
+//	    fmt.Println("progress", string(p))
+//	    return nil, nil
+//	})
+//	res, err := node.Call(ctx, "job.run", JobParams{File: "x.csv", Progress: progress})
+//
+// and the remote handler invokes it while it works:
+//
+//	func run(ctx context.Context, p JobParams) (Result, error) {
+//	    p.Progress.Notify(ctx, 50)
+//	    ...
+//	}
+//
+// A callback sent with a Call lives as long as that Call; otherwise it
+// lives until ReleaseCallback. All callbacks go with the connection.
+type Callback struct {
+	Method string `json:"$callback"`
+}
+
+// IsZero reports whether c is an empty handle (e.g. an optional param).
+func (c Callback) IsZero() bool {
+	return c.Method == ""
+}
+
+type callback struct {
+	fn    HandlerFunc
+	bound bool // owned by a Call, released when it returns
+}
+
+// NewCallback registers fn under a fresh, unguessable method name.
+func (node *Node) NewCallback(fn HandlerFunc) Callback {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	method := callbackPrefix + hex.EncodeToString(id[:])
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.callbacks[method] = &callback{fn: fn}
+	return Callback{Method: method}
+}
+
+// ReleaseCallback removes the method behind c. Later invocations fail
+// with ErrCodeMethodNotFound.
+func (node *Node) ReleaseCallback(c Callback) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	delete(node.callbacks, c.Method)
+}
+
+// Call invokes the callback on the Node that sent it. ctx must be (or
+// derive from) the ctx of the handler that received c.
+func (c Callback) Call(ctx context.Context, params any) (json.RawMessage, error) {
+	node, err := c.node(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return node.Call(ctx, c.Method, params)
+}
+
+// Notify invokes the callback without waiting for an answer.
+func (c Callback) Notify(ctx context.Context, params any) error {
+	node, err := c.node(ctx)
+	if err != nil {
+		return err
+	}
+	return node.Notify(ctx, c.Method, params)
+}
+
+func (c Callback) node(ctx context.Context) (*Node, error) {
+	if len(c.Method) <= len(callbackPrefix) || c.Method[:len(callbackPrefix)] != callbackPrefix {
+		return nil, fmt.Errorf("invalid callback handle %q", c.Method)
+	}
+	node := NodeFromContext(ctx)
+	if node == nil {
+		return nil, fmt.Errorf("callback %s: no Node in ctx, use the handler ctx", c.Method)
+	}
+	return node, nil
+}
+
+// bindCallbacks ties the unbound callbacks referenced in params to a
+// Call and returns the func that releases them.
+func (node *Node) bindCallbacks(params []byte) func() {
+	if !bytes.Contains(params, []byte(`"`+callbackPrefix)) {
+		return func() {}
+	}
+
+	var bound []string
+	node.mu.Lock()
+	for method, cb := range node.callbacks {
+		if !cb.bound && bytes.Contains(params, []byte(`"`+method+`"`)) {
+			cb.bound = true
+			bound = append(bound, method)
+		}
+	}
+	node.mu.Unlock()
+
+	return func() {
+		node.mu.Lock()
+		defer node.mu.Unlock()
+		for _, method := range bound {
+			delete(node.callbacks, method)
+		}
+	}
+}
+
+// releaseCallbacks drops all callbacks (connection lost): handles held
+// by the old peer must not reach a new connection.
+func (node *Node) releaseCallbacks() {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	clear(node.callbacks)
+}
+
+type nodeKey struct{}
+
+// NodeFromContext returns the Node that is running the current handler.
+func NodeFromContext(ctx context.Context) *Node {
+	node, _ := ctx.Value(nodeKey{}).(*Node)
+	return node
+}
@@ -0,0 +1,109 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type jobParams struct {
+	Steps    int      `json:"steps"`
+	Progress Callback `json:"progress"`
+}
+
+func TestCallback_BoundToCall(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	server := NewNode(serverConn, nil, "", nil)
+	client := NewNode(clientConn, nil, "", nil)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+
+	handles := make(chan Callback, 1)
+	server.Register("job.run", Typed(func(ctx context.Context, p jobParams) (string, error) {
+		for i := 1; i <= p.Steps; i++ {
+			// Calls wait for the caller's callback, so progress arrives in order.
+			if _, err := p.Progress.Call(ctx, i); err != nil {
+				return "", err
+			}
+		}
+		handles <- p.Progress
+		return "done", nil
+	}))
+
+	var mu sync.Mutex
+	var seen []int
+	progress := client.NewCallback(func(ctx context.Context, p json.RawMessage) (any, error) {
+		n, err := Bind[int](p)
+		mu.Lock()
+		seen = append(seen, n)
+		mu.Unlock()
+		return nil, err
+	})
+
+	res, err := client.Call(ctx, "job.run", jobParams{Steps: 3, Progress: progress})
+	if err != nil || string(res) != `"done"` {
+		t.Fatalf("job: %s %v", res, err)
+	}
+	if len(seen) != 3 || seen[2] != 3 {
+		t.Fatalf("progress %v", seen)
+	}
+	for _, m := range client.Methods() {
+		if m.Name == progress.Method {
+			t.Fatal("callbacks must not be listed")
+		}
+	}
+
+	// The Call is over, so is the callback.
+	handle := <-handles
+	sctx := context.WithValue(ctx, nodeKey{}, server)
+	_, err = handle.Call(sctx, 4)
+	if rpcErr, ok := err.(*RPCError); !ok || rpcErr.Code != ErrCodeMethodNotFound {
+		t.Fatalf("expected method not found after the call, got %v", err)
+	}
+
+	if _, err := (Callback{Method: "payment.refund"}).Call(sctx, nil); err == nil {
+		t.Fatal("handles outside the callback namespace must be rejected")
+	}
+}
+
+func TestCallback_ReleasedOnDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	client := NewNode(clientConn, nil, "", nil)
+	go client.Listen(ctx)
+
+	cb := client.NewCallback(func(ctx context.Context, p json.RawMessage) (any, error) { return nil, nil })
+	client.mu.RLock()
+	n := len(client.callbacks)
+	client.mu.RUnlock()
+	if n != 1 {
+		t.Fatalf("callback not registered")
+	}
+
+	serverConn.Close("gone")
+	deadline := time.Now().Add(time.Second)
+	for {
+		client.mu.RLock()
+		_, ok := client.callbacks[cb.Method]
+		client.mu.RUnlock()
+		if !ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("callback survived the connection")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// MethodCancel is sent as a notification when a caller gives up on a Call.
+// The receiving Node cancels the ctx of the matching handler.
+const MethodCancel = "rpc.cancel"
+
+type cancelParams struct {
+	ID json.RawMessage `json:"id"`
+}
+
+// FallbackFunc handles requests for which no method is registered.
+type FallbackFunc func(ctx context.Context, req Request) (any, error)
+
+// SetFallback installs fn for unknown methods (e.g. to relay them).
+// Without a fallback such requests are answered with ErrCodeMethodNotFound.
+func (node *Node) SetFallback(fn FallbackFunc) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.fallback = fn
+}
+
+// trackInflight derives a cancellable ctx for a request with id.
+func (node *Node) trackInflight(ctx context.Context, req Request) (context.Context, func()) {
+	if !req.hasID() {
+		return ctx, func() {}
+	}
+	key := idKey(req.ID)
+	ctx, cancel := context.WithCancel(ctx)
+
+	node.inflightMu.Lock()
+	node.inflight[key] = cancel
+	node.inflightMu.Unlock()
+
+	return ctx, func() {
+		node.inflightMu.Lock()
+		delete(node.inflight, key)
+		node.inflightMu.Unlock()
+		cancel()
+	}
+}
+
+func (node *Node) cancelInflight(params json.RawMessage) {
+	var p cancelParams
+	if err := json.Unmarshal(params, &p); err != nil {
+		return
+	}
+	node.inflightMu.Lock()
+	cancel, ok := node.inflight[idKey(p.ID)]
+	node.inflightMu.Unlock()
+	if ok {
+		node.Log.With("id", string(p.ID)).Debug("Request cancelled by caller")
+		cancel()
+	}
+}
+
+// sendCancel tells the peer that we no longer wait for id.
+// The caller's ctx is already done, so a short-lived one is used.
+func (node *Node) sendCancel(conn transport.Connection, id json.RawMessage) {
+	params, _ := json.Marshal(cancelParams{ID: id})
+	data, _ := json.Marshal(Request{JSONRPC: JRPCVERSION, Method: MethodCancel, Params: params})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_ = conn.Send(ctx, data)
+}
+
+func idKey(id json.RawMessage) string {
+	return strings.Trim(string(id), `"`)
+}
@@ -0,0 +1,35 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import "time"
+
+// Clock is the time source of a Node (reconnect backoff, latencies).
+// Tests swap in a fake clock (see nexiotest.FakeClock) to drive
+// backoff timings deterministically.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// SetClock replaces the time source; nil restores the system clock.
+func (node *Node) SetClock(c Clock) {
+	if c == nil {
+		c = systemClock{}
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.clock = c
+}
+
+func (node *Node) getClock() Clock {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	return node.clock
+}
@@ -11,8 +11,28 @@ and call methods on the partner (client role).
 Key features:
 - Support for call (request/response) and notify (fire-and-forget).
 - Robust error handling with standardized JSON-RPC error codes.
-- Generic bind function for type-safe unmarshaling without reflection.
-- Support for automatic reconnect logic on connection failure.
+- Generic Bind/Typed helpers that map object and positional (array) params onto structs.
+- Support for automatic reconnect logic on connection failure via any transport.Dialer
+  (WSProvider, or MemProvider of a simulated transport.MemNetwork in tests).
+- Binary attachments (CallWithAttachments) streamed as chunked binary frames.
+- Cancelled Calls notify the peer ("rpc.cancel"), which cancels the handler ctx.
+- Callbacks in params (NewCallback): ephemeral methods the peer may invoke
+  while the Call runs, released when it returns or the connection drops.
+- Remote objects (Export, Proxy): per-instance handles with refcounts and
+  optional leases, dropped on release, lease expiry or disconnect.
+- Idempotency keys (WithIdempotencyKey, SetIdempotency): repeated Calls get
+  the stored result or wait for the running one; results in a ResultCache.
+  Reusing a key with different params is an error.
+- W3C trace context propagation (request meta) with span hooks via Tracer.
+- Params validation against JSON Schemas (RegisterWithSchema, SchemaFor)
+  and introspection via "rpc.discover" (EnableIntrospection).
+- Call/request counters, latencies and pending calls via transport.Metrics.
+
+Typed client stubs and registration helpers can be generated from a Go
+interface with cmd/nexio-gen. As a lighter alternative, RegisterService
+registers all exported methods of a value via reflection:
+
+	err := node.RegisterService("payment", impl, rpc.WithNaming(rpc.NameLowerCamel))
 
 Example of registering a handler:
 
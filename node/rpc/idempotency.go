@@ -0,0 +1,342 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// MetaIdempotencyKey carries the idempotency key of a Call.
+const MetaIdempotencyKey = "idempotency-key"
+
+// ErrCodeIdempotencyMismatch answers a key reused with different params.
+const ErrCodeIdempotencyMismatch = -32002
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey makes every Call with ctx carry key. Retry the same
+// operation with the same key (e.g. cluster.Client failover does so
+// automatically) and a Node with SetIdempotency runs it only once.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the key set by WithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKey{}).(string)
+	return key
+}
+
+// NewIdempotencyKey returns a random key.
+func NewIdempotencyKey() string {
+	var raw [16]byte
+	_, _ = rand.Read(raw[:])
+	return hex.EncodeToString(raw[:])
+}
+
+// CachedResult is the stored result of a request together with a hash
+// of its params.
+type CachedResult struct {
+	Params string          `json:"params"`
+	Result json.RawMessage `json:"result"`
+}
+
+// ResultCache stores the results of requests with an idempotency key.
+// Entries expire after a TTL chosen by the implementation.
+type ResultCache interface {
+	Get(key string) (CachedResult, bool)
+	Put(key string, res CachedResult)
+}
+
+// Idempotency deduplicates requests by their idempotency key. A repeated
+// key gets the stored result, or waits for the execution still running,
+// instead of invoking the handler again. A repeated key with different
+// params is answered with ErrCodeIdempotencyMismatch. Failed executions
+// are not stored, so a retry after an error runs the handler again.
+//
+// Retries often arrive on a new connection, so one Idempotency is shared
+// by all Nodes of a service:
+//
+//	idem := rpc.NewIdempotency(rpc.NewMemoryCache(24 * time.Hour))
+//	node.SetIdempotency(idem) // for every accepted connection
+type Idempotency struct {
+	cache ResultCache
+
+	mu      sync.Mutex
+	running map[string]*flight
+}
+
+type flight struct {
+	params string
+	done   chan struct{}
+	result json.RawMessage
+	err    *RPCError
+}
+
+func NewIdempotency(cache ResultCache) *Idempotency {
+	return &Idempotency{cache: cache, running: make(map[string]*flight)}
+}
+
+// SetIdempotency enables deduplication of Calls by idempotency key.
+// nil switches it off.
+func (node *Node) SetIdempotency(idem *Idempotency) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.idem = idem
+}
+
+func (node *Node) getIdempotency() *Idempotency {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	return node.idem
+}
+
+// do runs fn once per key. Keys are scoped by method.
+func (i *Idempotency) do(ctx context.Context, method, key string, params json.RawMessage, fn func() (json.RawMessage, *RPCError)) (json.RawMessage, *RPCError) {
+	key = method + "\x00" + key
+	hash := paramsHash(params)
+
+	i.mu.Lock()
+	if res, ok := i.cache.Get(key); ok {
+		i.mu.Unlock()
+		if res.Params != hash {
+			return nil, NewRPCError(ErrCodeIdempotencyMismatch, "idempotency key reused with different params")
+		}
+		LogFromContext(ctx).With("req.Method", method).Debug("Idempotent request answered from cache")
+		return res.Result, nil
+	}
+	if f, ok := i.running[key]; ok {
+		i.mu.Unlock()
+		if f.params != hash {
+			return nil, NewRPCError(ErrCodeIdempotencyMismatch, "idempotency key reused with different params")
+		}
+		select {
+		case <-f.done:
+			return f.result, f.err
+		case <-ctx.Done():
+			return nil, NewRPCError(ErrCodeInternalError, ctx.Err().Error())
+		}
+	}
+	f := &flight{params: hash, done: make(chan struct{})}
+	i.running[key] = f
+	i.mu.Unlock()
+
+	f.result, f.err = fn()
+
+	// Put may hit the disk; the flight still answers repeats meanwhile.
+	if f.err == nil {
+		i.cache.Put(key, CachedResult{Params: hash, Result: f.result})
+	}
+	i.mu.Lock()
+	delete(i.running, key)
+	i.mu.Unlock()
+	close(f.done)
+	return f.result, f.err
+}
+
+// paramsHash identifies params regardless of insignificant whitespace.
+func paramsHash(params json.RawMessage) string {
+	var buf bytes.Buffer
+	if json.Compact(&buf, params) != nil {
+		buf.Reset()
+		buf.Write(params)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCache is a ResultCache in memory.
+type MemoryCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	puts    int
+}
+
+type cacheEntry struct {
+	Key string `json:"key"`
+	CachedResult
+	Expires time.Time `json:"expires"`
+}
+
+// NewMemoryCache keeps results for ttl.
+func NewMemoryCache(ttl time.Duration) *MemoryCache {
+	return &MemoryCache{ttl: ttl, now: time.Now, entries: make(map[string]cacheEntry)}
+}
+
+func (c *MemoryCache) Get(key string) (CachedResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.Expires) {
+		return CachedResult{}, false
+	}
+	return e.CachedResult, true
+}
+
+func (c *MemoryCache) Put(key string, res CachedResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(cacheEntry{Key: key, CachedResult: res, Expires: c.now().Add(c.ttl)})
+}
+
+// put stores e and now and then sweeps expired entries. Callers hold mu.
+func (c *MemoryCache) put(e cacheEntry) {
+	c.entries[e.Key] = e
+	c.puts++
+	if c.puts%1000 == 0 {
+		now := c.now()
+		for k, e := range c.entries {
+			if !now.Before(e.Expires) {
+				delete(c.entries, k)
+			}
+		}
+	}
+}
+
+// Len returns the number of stored results, including expired ones not
+// swept yet.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// FileCache is a MemoryCache that also appends every result to a file,
+// so that stored results survive a restart. Failed writes are logged;
+// the result is still served from memory.
+type FileCache struct {
+	*MemoryCache
+	path string
+	Log  transport.LogSink
+
+	fileMu sync.Mutex // file I/O, Get does not wait for it
+	file   *os.File
+}
+
+// OpenFileCache loads the unexpired results from path (created if
+// missing) and keeps new ones for ttl.
+func OpenFileCache(path string, ttl time.Duration, logger transport.LogSink) (*FileCache, error) {
+	c := &FileCache{MemoryCache: NewMemoryCache(ttl), path: path, Log: &transport.SilentLogger{}}
+	if logger != nil {
+		c.Log = logger
+	}
+
+	f, err := os.Open(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if err == nil {
+		dec := json.NewDecoder(bufio.NewReader(f))
+		now := c.now()
+		for {
+			var e cacheEntry
+			if err := dec.Decode(&e); err != nil {
+				break // EOF or the torn last line of a crash
+			}
+			if now.Before(e.Expires) {
+				c.entries[e.Key] = e
+			}
+		}
+		f.Close()
+	}
+
+	if err := c.rewrite(c.snapshot()); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *FileCache) Put(key string, res CachedResult) {
+	c.mu.Lock()
+	e := cacheEntry{Key: key, CachedResult: res, Expires: c.now().Add(c.ttl)}
+	c.put(e)
+	swept := c.puts%1000 == 0
+	c.mu.Unlock()
+
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+	if c.file == nil {
+		return
+	}
+	line, _ := json.Marshal(e)
+	_, err := c.file.Write(append(line, '\n'))
+	if err == nil {
+		err = c.file.Sync()
+	}
+	if err != nil {
+		c.Log.With("path", c.path).With("error", err).Error("Storing result failed")
+	}
+	// Drop the expired lines whenever the memory side was swept.
+	if swept {
+		if err := c.rewrite(c.snapshot()); err != nil {
+			c.Log.With("path", c.path).With("error", err).Error("Rewriting result file failed")
+		}
+	}
+}
+
+// Close closes the file; the cache keeps working in memory.
+func (c *FileCache) Close() error {
+	c.fileMu.Lock()
+	defer c.fileMu.Unlock()
+	if c.file == nil {
+		return nil
+	}
+	err := c.file.Close()
+	c.file = nil
+	return err
+}
+
+// snapshot returns the current entries.
+func (c *FileCache) snapshot() []cacheEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entries := make([]cacheEntry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// rewrite replaces the file by entries. Callers hold fileMu (or own c
+// exclusively); entries taken after fileMu are complete, later Puts
+// append to the new file.
+func (c *FileCache) rewrite(entries []cacheEntry) error {
+	tmp := c.path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		line, _ := json.Marshal(e)
+		w.Write(append(line, '\n'))
+	}
+	if err := errors.Join(w.Flush(), f.Sync(), f.Close()); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, c.path); err != nil {
+		return err
+	}
+	if c.file != nil {
+		c.file.Close()
+	}
+	c.file, err = os.OpenFile(c.path, os.O_WRONLY|os.O_APPEND, 0o644)
+	return err
+}
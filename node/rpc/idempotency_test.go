@@ -0,0 +1,242 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// idemPair connects a new client to a server Node sharing idem.
+func idemPair(ctx context.Context, idem *Idempotency, h HandlerFunc) *Node {
+	clientConn, serverConn := transport.NewMemPair()
+	server := NewNode(serverConn, nil, "", nil)
+	server.SetIdempotency(idem)
+	server.Register("payment.process", h)
+	client := NewNode(clientConn, nil, "", nil)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+	return client
+}
+
+func TestIdempotency_Repeat(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	fail := true
+	idem := NewIdempotency(NewMemoryCache(time.Minute))
+	handler := func(ctx context.Context, p json.RawMessage) (any, error) {
+		if fail {
+			fail = false
+			return nil, errors.New("card service down")
+		}
+		return runs.Add(1), nil
+	}
+	client := idemPair(ctx, idem, handler)
+
+	key := NewIdempotencyKey()
+	kctx := WithIdempotencyKey(ctx, key)
+	if _, err := client.Call(kctx, "payment.process", nil); err == nil {
+		t.Fatal("expected the first attempt to fail")
+	}
+	// Errors are not stored, the retry runs the handler.
+	first, err := client.Call(kctx, "payment.process", nil)
+	if err != nil || string(first) != "1" {
+		t.Fatalf("retry: %s %v", first, err)
+	}
+
+	// The retry after a reconnect arrives on another connection.
+	other := idemPair(ctx, idem, handler)
+	again, err := other.Call(kctx, "payment.process", nil)
+	if err != nil || string(again) != "1" {
+		t.Fatalf("repeat: %s %v", again, err)
+	}
+
+	if res, _ := client.Call(WithIdempotencyKey(ctx, NewIdempotencyKey()), "payment.process", nil); string(res) != "2" {
+		t.Fatalf("new key: %s", res)
+	}
+	if res, _ := client.Call(ctx, "payment.process", nil); string(res) != "3" {
+		t.Fatalf("no key: %s", res)
+	}
+}
+
+func TestIdempotency_ParamsMismatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	idem := NewIdempotency(NewMemoryCache(time.Minute))
+	client := idemPair(ctx, idem, func(ctx context.Context, p json.RawMessage) (any, error) {
+		runs.Add(1)
+		return p, nil
+	})
+
+	kctx := WithIdempotencyKey(ctx, NewIdempotencyKey())
+	if res, err := client.Call(kctx, "payment.process", map[string]int{"amount": 10}); err != nil || string(res) != `{"amount":10}` {
+		t.Fatalf("first: %s %v", res, err)
+	}
+	_, err := client.Call(kctx, "payment.process", map[string]int{"amount": 99})
+	if rpcErr, ok := err.(*RPCError); !ok || rpcErr.Code != ErrCodeIdempotencyMismatch {
+		t.Fatalf("expected a mismatch error, got %v", err)
+	}
+	if res, err := client.Call(kctx, "payment.process", map[string]int{"amount": 10}); err != nil || string(res) != `{"amount":10}` {
+		t.Fatalf("repeat: %s %v", res, err)
+	}
+	if runs.Load() != 1 {
+		t.Fatalf("handler ran %d times", runs.Load())
+	}
+}
+
+// slowCache blocks every Put until release is closed.
+type slowCache struct {
+	*MemoryCache
+	release chan struct{}
+}
+
+func (c slowCache) Put(key string, res CachedResult) {
+	<-c.release
+	c.MemoryCache.Put(key, res)
+}
+
+func TestIdempotency_PutOutsideLock(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cache := slowCache{MemoryCache: NewMemoryCache(time.Minute), release: make(chan struct{})}
+	ran := make(chan string, 2)
+	client := idemPair(ctx, NewIdempotency(cache), func(ctx context.Context, p json.RawMessage) (any, error) {
+		ran <- string(p)
+		return p, nil
+	})
+
+	errs := make(chan error, 2)
+	for _, key := range []string{"a", "b"} {
+		go func() {
+			_, err := client.Call(WithIdempotencyKey(ctx, key), "payment.process", key)
+			errs <- err
+		}()
+		// While the first result is being stored, the second key runs.
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatalf("handler for %s blocked by a pending Put", key)
+		}
+	}
+	close(cache.release)
+	for range 2 {
+		if err := <-errs; err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFileCache_LogsWriteErrors(t *testing.T) {
+	sink := newFieldSink()
+	c, err := OpenFileCache(filepath.Join(t.TempDir(), "results"), time.Minute, sink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.file.Close() // writes fail from now on
+	c.Put("k", CachedResult{Result: json.RawMessage(`1`)})
+
+	if res, ok := c.Get("k"); !ok || string(res.Result) != "1" {
+		t.Fatalf("Expected the result in memory, got %s %v", res.Result, ok)
+	}
+	sink.mu.Lock()
+	defer sink.mu.Unlock()
+	if fields, ok := sink.logged["Storing result failed"]; !ok || fields["error"] == nil {
+		t.Fatalf("Expected the write error to be logged, got %v", sink.logged)
+	}
+}
+
+func TestIdempotency_WaitsForRunning(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var runs atomic.Int32
+	release := make(chan struct{})
+	client := idemPair(ctx, NewIdempotency(NewMemoryCache(time.Minute)), func(ctx context.Context, p json.RawMessage) (any, error) {
+		n := runs.Add(1)
+		<-release
+		return n, nil
+	})
+
+	kctx := WithIdempotencyKey(ctx, "order-42")
+	var wg sync.WaitGroup
+	results := make([]string, 3)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			res, err := client.Call(kctx, "payment.process", nil)
+			if err != nil {
+				t.Error(err)
+			}
+			results[i] = string(res)
+		}(i)
+	}
+	waitFor(t, "first execution", func() bool { return runs.Load() == 1 })
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if runs.Load() != 1 {
+		t.Fatalf("handler ran %d times", runs.Load())
+	}
+	for _, r := range results {
+		if r != "1" {
+			t.Fatalf("unexpected results %v", results)
+		}
+	}
+}
+
+func TestMemoryCache_TTL(t *testing.T) {
+	now := time.Now()
+	c := NewMemoryCache(time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Put("k", CachedResult{Result: json.RawMessage(`1`)})
+	if _, ok := c.Get("k"); !ok {
+		t.Fatal("missing fresh entry")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("k"); ok {
+		t.Fatal("expired entry returned")
+	}
+}
+
+func TestFileCache_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results")
+	c, err := OpenFileCache(path, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Put("paid", CachedResult{Params: "h", Result: json.RawMessage(`{"tx":7}`)})
+	c.Close()
+
+	c, err = OpenFileCache(path, time.Minute, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, ok := c.Get("paid"); !ok || string(res.Result) != `{"tx":7}` || res.Params != "h" {
+		t.Fatalf("after reopen: %s %v", res, ok)
+	}
+	c.Close()
+
+	// Expired entries are not loaded.
+	c, _ = OpenFileCache(path, -time.Second, nil)
+	c.Put("old", CachedResult{Result: json.RawMessage(`1`)})
+	c.Close()
+	c, _ = OpenFileCache(path, time.Minute, nil)
+	defer c.Close()
+	if _, ok := c.Get("old"); ok || c.Len() != 1 {
+		t.Fatalf("expected only the fresh entry, got %d", c.Len())
+	}
+}
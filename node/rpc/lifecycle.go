@@ -0,0 +1,80 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"errors"
+)
+
+// OnConnect registers fn to run after the Node has (re)established its
+// connection. Hooks run in their own goroutine, so they may Call the peer.
+func (node *Node) OnConnect(fn func(ctx context.Context)) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.onConnect = append(node.onConnect, fn)
+}
+
+// OnDisconnect registers fn to run when the connection is lost,
+// after pending calls have been cancelled.
+func (node *Node) OnDisconnect(fn func(err error)) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.onDisconnect = append(node.onDisconnect, fn)
+}
+
+func (node *Node) fireConnect(ctx context.Context) {
+	node.mu.RLock()
+	hooks := append([]func(context.Context){}, node.onConnect...)
+	node.mu.RUnlock()
+	for _, fn := range hooks {
+		go fn(ctx)
+	}
+}
+
+func (node *Node) fireDisconnect(err error) {
+	node.mu.RLock()
+	hooks := append([]func(error){}, node.onDisconnect...)
+	node.mu.RUnlock()
+	for _, fn := range hooks {
+		fn(err)
+	}
+}
+
+// Connected reports whether the Node currently has a connection.
+func (node *Node) Connected() bool {
+	return node.connection() != nil
+}
+
+// Pending returns the number of Calls waiting for a response.
+func (node *Node) Pending() int {
+	node.pendingMu.Lock()
+	defer node.pendingMu.Unlock()
+	return len(node.pending)
+}
+
+// SendError is returned by Call and Notify when the transport failed to
+// send the request, so it never reached the peer.
+type SendError struct {
+	Err error
+}
+
+func (e *SendError) Error() string { return e.Err.Error() }
+func (e *SendError) Unwrap() error { return e.Err }
+
+// IsConnectionError reports whether err means the Call did not reach
+// the peer (a *SendError) or the connection broke before the answer
+// arrived (ErrConnectionLostError). Such calls are candidates for a
+// retry on another connection; any other error is not.
+func IsConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var rpcErr *RPCError
+	if errors.As(err, &rpcErr) {
+		return rpcErr.Code == ErrConnectionLostError
+	}
+	var sendErr *SendError
+	return errors.As(err, &sendErr)
+}
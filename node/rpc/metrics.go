@@ -0,0 +1,64 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"strings"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Metric names reported by a Node.
+const (
+	MetricCalls           = "nexio_rpc_calls_total"           // outgoing, labels: method, code
+	MetricCallDuration    = "nexio_rpc_call_duration_seconds" // outgoing, labels: method, code
+	MetricRequests        = "nexio_rpc_requests_total"        // incoming, labels: method, code
+	MetricRequestDuration = "nexio_rpc_request_duration_seconds"
+	MetricNotifications   = "nexio_rpc_notifications_sent_total" // labels: method, code
+	MetricPending         = "nexio_rpc_pending_calls"
+	MetricReconnects      = "nexio_rpc_reconnects_total"
+)
+
+// Values of the "code" label besides the numeric JSON-RPC error codes.
+const (
+	CodeOK        = "ok"
+	CodeTransport = "transport"
+	CodeCanceled  = "canceled"
+)
+
+// MethodUnknown is the "method" label of requests no handler took. The
+// names come from the peer and would otherwise grow the label set
+// without bound.
+const MethodUnknown = "unknown"
+
+// SetMetrics installs a metrics sink. nil switches reporting off.
+func (node *Node) SetMetrics(m transport.Metrics) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.metrics = transport.MetricsOrNoop(m)
+}
+
+func (node *Node) getMetrics() transport.Metrics {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	return node.metrics
+}
+
+// metricMethod returns the "method" label of method: callbacks have
+// random names and share one label.
+func metricMethod(method string) string {
+	if strings.HasPrefix(method, callbackPrefix) {
+		return callbackPrefix
+	}
+	return method
+}
+
+// observe counts one call/request and records its latency.
+func (node *Node) observe(counter, histogram, method, code string, start time.Time) {
+	method = metricMethod(method)
+	m := node.getMetrics()
+	m.Counter(counter, 1, "method", method, "code", code)
+	m.Observe(histogram, node.getClock().Now().Sub(start).Seconds(), "method", method, "code", code)
+}
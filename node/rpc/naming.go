@@ -0,0 +1,53 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NamingFunc builds the wire method name from a namespace and a Go method name.
+type NamingFunc func(namespace, method string) string
+
+// NameExact keeps the Go name: "payment.ProcessOrder".
+func NameExact(namespace, method string) string {
+	return join(namespace, method)
+}
+
+// NameLowerCamel lowers the first rune: "payment.processOrder".
+func NameLowerCamel(namespace, method string) string {
+	r := []rune(method)
+	// keep leading acronyms together: "URLFor" -> "urlFor"
+	for i := 0; i < len(r) && unicode.IsUpper(r[i]); i++ {
+		if i > 0 && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+			break
+		}
+		r[i] = unicode.ToLower(r[i])
+	}
+	return join(namespace, string(r))
+}
+
+// NameSnake converts to snake case: "payment.process_order".
+func NameSnake(namespace, method string) string {
+	var sb strings.Builder
+	r := []rune(method)
+	for i, c := range r {
+		if unicode.IsUpper(c) {
+			if i > 0 && (unicode.IsLower(r[i-1]) || (i+1 < len(r) && unicode.IsLower(r[i+1]))) {
+				sb.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		sb.WriteRune(c)
+	}
+	return join(namespace, sb.String())
+}
+
+func join(namespace, method string) string {
+	if namespace == "" {
+		return method
+	}
+	return namespace + "." + method
+}
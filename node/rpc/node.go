@@ -6,9 +6,12 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/georghagn/nexio/node/transport"
@@ -20,16 +23,47 @@ type Node struct {
 	connMu sync.RWMutex //Protects the connection during the exchange.
 	conn   transport.Connection
 
-	handlers map[string]HandlerFunc
-	mu       sync.RWMutex
+	handlers  map[string]HandlerFunc
+	schemas   map[string]*Schema
+	callbacks map[string]*callback // ephemeral methods, see NewCallback
+	fallback  FallbackFunc
+	mu        sync.RWMutex
+
+	// Exported objects, see Export
+	objects   map[string]*exported
+	objectIDs map[any]string
+	objectMu  sync.Mutex
+
+	inflight   map[string]context.CancelFunc // running handlers by request id
+	inflightMu sync.Mutex
+
+	// Attachments: incoming buffers and outgoing stream ids
+	streams     map[uint64]*inStream
+	streamMu    sync.Mutex
+	nextStream  uint64
+	sendLock    fairLock
+	streamLimit int64        // max buffered bytes per incoming stream
+	bufferLimit int64        // max buffered bytes of all incoming streams
+	streamMax   int          // max open incoming streams
+	buffered    atomic.Int64 // currently buffered bytes of all incoming streams
 
 	pending   map[string]pendingRequest
 	pendingMu sync.Mutex
 	nextID    uint64
 
 	// For the reconnect mechanism
-	dialAddr string
-	provider *transport.WSProvider
+	dialAddr   string
+	provider   transport.Dialer
+	backoff    time.Duration // first retry delay
+	maxBackoff time.Duration
+
+	tracer  Tracer
+	metrics transport.Metrics
+	clock   Clock
+	idem    *Idempotency
+
+	onConnect    []func(ctx context.Context)
+	onDisconnect []func(err error)
 
 	Log transport.LogSink
 }
@@ -39,18 +73,34 @@ type pendingRequest struct {
 	done chan Response
 }
 
+// NewNode creates a Node on conn. If dialAddr is set, provider dials it
+// whenever there is no connection - initially when conn is nil and after
+// every connection loss. Accepted (server side) Nodes pass nil and "".
 func NewNode(
 	conn transport.Connection,
-	provider *transport.WSProvider,
+	provider transport.Dialer,
 	dialAddr string,
 	logger transport.LogSink) *Node {
 	n := &Node{
-		conn:     conn,
-		handlers: make(map[string]HandlerFunc),
-		pending:  make(map[string]pendingRequest),
-		provider: provider,
-		dialAddr: dialAddr,
-		Log:      &transport.SilentLogger{},
+		conn:        conn,
+		handlers:    make(map[string]HandlerFunc),
+		schemas:     make(map[string]*Schema),
+		callbacks:   make(map[string]*callback),
+		objects:     make(map[string]*exported),
+		objectIDs:   make(map[any]string),
+		inflight:    make(map[string]context.CancelFunc),
+		streams:     make(map[uint64]*inStream),
+		streamLimit: DefaultStreamBuffer,
+		bufferLimit: DefaultNodeBuffer,
+		streamMax:   DefaultMaxStreams,
+		pending:     make(map[string]pendingRequest),
+		provider:    provider,
+		dialAddr:    dialAddr,
+		backoff:     1 * time.Second,
+		maxBackoff:  30 * time.Second,
+		metrics:     transport.NoopMetrics{},
+		clock:       systemClock{},
+		Log:         &transport.SilentLogger{},
 	}
 	if logger != nil {
 		n.Log = logger
@@ -60,6 +110,16 @@ func NewNode(
 
 // Call sends a request and blocks until the response arrives.
 func (node *Node) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	return node.call(ctx, method, params, callOptions{})
+}
+
+// callOptions carry the extras of specialised calls (e.g. attachments).
+type callOptions struct {
+	meta      map[string]string
+	afterSend func(conn transport.Connection) // runs once the request is on the wire
+}
+
+func (node *Node) call(ctx context.Context, method string, params any, opts callOptions) (json.RawMessage, error) {
 	// 1. Secure connection
 	node.connMu.RLock()
 	currentConn := node.conn
@@ -67,7 +127,7 @@ func (node *Node) Call(ctx context.Context, method string, params any) (json.Raw
 
 	// If a reconnect is in progress or the connection is lost: No panic!
 	if currentConn == nil {
-		return nil, NewRPCError(ErrCodeInternalError, "The connection is currently being re-established.")
+		return nil, NewRPCError(ErrConnectionLostError, "The connection is currently being re-established.")
 	}
 
 	// 2. ID generieren und in pending-Map registrieren
@@ -77,39 +137,66 @@ func (node *Node) Call(ctx context.Context, method string, params any) (json.Raw
 	idJSON, _ := json.Marshal(idStr)
 	ch := make(chan Response, 1)
 	node.pending[idStr] = pendingRequest{done: ch}
+	node.getMetrics().Gauge(MetricPending, float64(len(node.pending)))
 	node.pendingMu.Unlock()
 
 	// Cleaning up after the call
 	defer func() {
 		node.pendingMu.Lock()
 		delete(node.pending, idStr)
+		node.getMetrics().Gauge(MetricPending, float64(len(node.pending)))
 		node.pendingMu.Unlock()
 	}()
 
-	// 3. Prepare request
+	// 3. Prepare request (incl. trace context)
 	pBytes, _ := json.Marshal(params)
+	defer node.bindCallbacks(pBytes)()
 	req := Request{
 		JSONRPC: JRPCVERSION,
 		Method:  method,
 		Params:  pBytes,
 		ID:      idJSON,
+		Meta:    make(map[string]string),
+	}
+	for k, v := range opts.meta {
+		req.Meta[k] = v
+	}
+	if key := IdempotencyKeyFromContext(ctx); key != "" {
+		req.Meta[MetaIdempotencyKey] = key
+	}
+	spanCtx, span := node.startClientSpan(ctx, method, false, req.Meta)
+
+	start := node.getClock().Now()
+	finish := func(code string, err error) {
+		node.endSpan(spanCtx, span, err)
+		node.observe(MetricCalls, MetricCallDuration, method, code, start)
 	}
 
 	data, _ := json.Marshal(req)
 
 	// 4. Send via COPY of the connection
 	if err := currentConn.Send(ctx, data); err != nil {
-		return nil, err
+		finish(CodeTransport, err)
+		return nil, &SendError{Err: err}
+	}
+	if opts.afterSend != nil {
+		opts.afterSend(currentConn)
 	}
 
 	// 5. Wait for answer
 	select {
 	case resp := <-ch:
 		if resp.Error != nil {
-			return nil, fmt.Errorf("RPC error %d: %s", resp.Error.Code, resp.Error.Message)
+			// The *RPCError is returned as is, so callers can inspect Code and Data.
+			finish(strconv.Itoa(resp.Error.Code), resp.Error)
+			return nil, resp.Error
 		}
+		finish(CodeOK, nil)
 		return resp.Result, nil
 	case <-ctx.Done():
+		// Let the peer stop working on it.
+		node.sendCancel(currentConn, idJSON)
+		finish(CodeCanceled, ctx.Err())
 		return nil, ctx.Err()
 	}
 }
@@ -129,7 +216,7 @@ func (node *Node) Listen(ctx context.Context) error {
 
 		if currentConn == nil {
 			// if we don't have an address (server-side), we can't reconnect.
-			if node.dialAddr == "" {
+			if node.dialAddr == "" || node.provider == nil {
 				return fmt.Errorf("Connection lost and no reconnect address available")
 			}
 
@@ -137,6 +224,7 @@ func (node *Node) Listen(ctx context.Context) error {
 			if err := node.attemptReconnect(ctx); err != nil {
 				return err
 			}
+			node.fireConnect(ctx)
 			continue
 		}
 
@@ -152,17 +240,35 @@ func (node *Node) Listen(ctx context.Context) error {
 
 			// 2. Cancel all pending calls (so they don't get stuck)
 			node.cleanupPendingRequests("Connection lost")
+			node.abortStreams()
+			node.releaseCallbacks()
+			node.releaseObjects()
+			node.fireDisconnect(err)
 
 			continue
 
 		}
 
+		// Attachment chunks must stay in order, they are only buffered here.
+		if transport.IsBinaryFrame(data) {
+			node.handleChunk(data)
+			continue
+		}
+		// Streams are registered before their chunks arrive, chunks of
+		// unannounced streams are dropped.
+		node.announceStreams(data)
+
 		go node.handleIncoming(ctx, data)
 	}
 }
 
 // Notify sends a notification to which no response is expected (no ID).
 func (node *Node) Notify(ctx context.Context, method string, params any) error {
+	return node.NotifyWithMeta(ctx, method, params, nil)
+}
+
+// NotifyWithMeta is Notify plus extra request meta (see CallWithMeta).
+func (node *Node) NotifyWithMeta(ctx context.Context, method string, params any, meta map[string]string) error {
 	// 1. Securely intercept connection (Read-Lock)
 	node.connMu.RLock()
 	currentConn := node.conn
@@ -170,7 +276,7 @@ func (node *Node) Notify(ctx context.Context, method string, params any) error {
 
 	// If a reconnect is currently in progress: Report the error instead of panicking
 	if currentConn == nil {
-		return NewRPCError(ErrCodeInternalError, "Notification failed: Reconnecting")
+		return NewRPCError(ErrConnectionLostError, "Notification failed: Reconnecting")
 	}
 
 	// 2. Process parameters
@@ -184,24 +290,44 @@ func (node *Node) Notify(ctx context.Context, method string, params any) error {
 		JSONRPC: JRPCVERSION,
 		Method:  method,
 		Params:  pBytes,
+		Meta:    make(map[string]string),
+	}
+	for k, v := range meta {
+		req.Meta[k] = v
 	}
+	spanCtx, span := node.startClientSpan(ctx, method, true, req.Meta)
 
 	data, err := json.Marshal(req)
 	if err != nil {
+		node.endSpan(spanCtx, span, err)
 		return NewRPCError(ErrCodeJSONError, []byte(err.Error()))
 	}
 
 	// 4. Send via secure connection
-	if err := currentConn.Send(ctx, data); err != nil {
-		return err // Here we are directly returning the network error.
+	err = currentConn.Send(ctx, data)
+	node.endSpan(spanCtx, span, err)
+	if err != nil {
+		node.getMetrics().Counter(MetricNotifications, 1, "method", metricMethod(method), "code", CodeTransport)
+		return &SendError{Err: err}
 	}
-
+	node.getMetrics().Counter(MetricNotifications, 1, "method", metricMethod(method), "code", CodeOK)
 	return nil
 }
 
+// SetReconnectBackoff sets the delay before the second dial attempt and
+// its upper bound; the delay doubles after every failed attempt.
+// The defaults are 1s and 30s.
+func (node *Node) SetReconnectBackoff(initial, max time.Duration) {
+	node.connMu.Lock()
+	defer node.connMu.Unlock()
+	node.backoff = initial
+	node.maxBackoff = max
+}
+
 func (node *Node) attemptReconnect(ctx context.Context) error {
-	backoff := 1 * time.Second
-	maxBackoff := 30 * time.Second
+	node.connMu.RLock()
+	backoff, maxBackoff := node.backoff, node.maxBackoff
+	node.connMu.RUnlock()
 
 	for {
 		select {
@@ -212,6 +338,7 @@ func (node *Node) attemptReconnect(ctx context.Context) error {
 			newConn, err := node.provider.Dial(ctx, node.dialAddr)
 			if err == nil {
 				node.Log.Info("Reconnect successful!")
+				node.getMetrics().Counter(MetricReconnects, 1)
 				node.connMu.Lock()
 				node.conn = newConn
 				node.connMu.Unlock()
@@ -220,7 +347,11 @@ func (node *Node) attemptReconnect(ctx context.Context) error {
 
 			node.Log.With("err", err).With("backoff", backoff).Error("Failed, next attempt")
 
-			time.Sleep(backoff)
+			select {
+			case <-node.getClock().After(backoff):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
 			backoff *= 2
 			if backoff > maxBackoff {
 				backoff = maxBackoff
@@ -245,37 +376,112 @@ func (node *Node) handleIncoming(ctx context.Context, data []byte) {
 	}
 }
 
-func (node *Node) processRequest(ctx context.Context, req Request) {
+func (node *Node) processRequest(connCtx context.Context, req Request) {
+	if req.Method == MethodCancel {
+		node.cancelInflight(req.Params)
+		return
+	}
+
 	node.mu.RLock()
 	handler, ok := node.handlers[req.Method]
+	schema := node.schemas[req.Method]
+	if cb, isCallback := node.callbacks[req.Method]; !ok && isCallback {
+		handler, ok = cb.fn, true
+	}
+	if h := node.objectHandler(req.Method); !ok && h != nil {
+		handler, ok = h, true
+	}
+	if !ok && node.fallback != nil {
+		fallback := node.fallback
+		handler = func(ctx context.Context, params json.RawMessage) (any, error) {
+			return fallback(ctx, req)
+		}
+		ok = true
+	}
 	node.mu.RUnlock()
 
+	// The handler ctx ends when the caller sends "rpc.cancel".
+	ctx, done := node.trackInflight(connCtx, req)
+	defer done()
+
+	ctx, release := node.withAttachments(ctx, req)
+	defer release()
+
+	ctx = context.WithValue(ctx, requestKey{}, req)
+	ctx = context.WithValue(ctx, nodeKey{}, node)
+
+	// Restore the caller's trace, the handler sees it in its ctx.
+	ctx, span := node.startServerSpan(ctx, req)
+	start := node.getClock().Now()
+
 	var resp Response
 	resp.JSONRPC = JRPCVERSION
 	resp.ID = req.ID
 
 	if !ok {
 		resp.Error = NewRPCError(ErrCodeMethodNotFound, req.Method)
+	} else if violations := schema.check(req.Params); len(violations) > 0 {
+		resp.Error = NewRPCError(ErrCodeInvalidParams, violations)
 	} else {
-		result, err := handler(ctx, req.Params)
-		if err != nil {
-			// Here we use the Data field for the error message from Go
-			resp.Error = NewRPCError(ErrCodeInternalError, err.Error())
-		} else {
+		run := func() (json.RawMessage, *RPCError) {
+			result, err := handler(ctx, req.Params)
+			var rpcErr *RPCError
+			if errors.As(err, &rpcErr) {
+				// Handlers (and Bind) may answer with a specific code.
+				return nil, rpcErr
+			} else if err != nil {
+				// Here we use the Data field for the error message from Go
+				return nil, NewRPCError(ErrCodeInternalError, err.Error())
+			}
 			resBytes, _ := json.Marshal(result)
-			resp.Result = resBytes
+			return resBytes, nil
 		}
+		if key, idem := req.Meta[MetaIdempotencyKey], node.getIdempotency(); key != "" && idem != nil && req.hasID() {
+			resp.Result, resp.Error = idem.do(ctx, req.Method, key, req.Params, run)
+		} else {
+			resp.Result, resp.Error = run()
+		}
+	}
+
+	label := req.Method
+	if !ok || resp.Error != nil && resp.Error.Code == ErrCodeMethodNotFound {
+		label = MethodUnknown
+	}
+	if resp.Error != nil {
+		node.endSpan(ctx, span, resp.Error)
+		node.observe(MetricRequests, MetricRequestDuration, label, strconv.Itoa(resp.Error.Code), start)
+	} else {
+		node.endSpan(ctx, span, nil)
+		node.observe(MetricRequests, MetricRequestDuration, label, CodeOK, start)
 	}
 
 	// IMPORTANT: We will only send a reply if an ID is provided.
-	if req.ID != nil && string(req.ID) != "null" {
+	if req.hasID() {
 		respBytes, _ := json.Marshal(resp)
-		_ = node.conn.Send(ctx, respBytes)
+		if conn := node.connection(); conn != nil {
+			_ = conn.Send(connCtx, respBytes)
+		}
 	} else {
-		node.Log.With("req.Method", req.Method).Info("Notification received")
+		LogFromContext(ctx).With("req.Method", req.Method).Info("Notification received")
 	}
 }
 
+type requestKey struct{}
+
+// RequestFromContext returns the request a handler is processing,
+// e.g. to read its Meta or tell calls from notifications.
+func RequestFromContext(ctx context.Context) (Request, bool) {
+	req, ok := ctx.Value(requestKey{}).(Request)
+	return req, ok
+}
+
+// connection returns the current connection (nil while reconnecting).
+func (node *Node) connection() transport.Connection {
+	node.connMu.RLock()
+	defer node.connMu.RUnlock()
+	return node.conn
+}
+
 func (node *Node) processResponse(resp Response) {
 	// Removes quotation marks if present.
 	idStr := strings.Trim(string(resp.ID), `"`)
@@ -295,7 +501,7 @@ func (node *Node) cleanupPendingRequests(reason string) {
 	for id, req := range node.pending {
 		req.done <- Response{
 			ID:    json.RawMessage(id),
-			Error: NewRPCError(ErrCodeInternalError, reason),
+			Error: NewRPCError(ErrConnectionLostError, reason),
 		}
 		delete(node.pending, id)
 	}
@@ -13,6 +13,20 @@ type Request struct {
 	Method  string          `json:"method"`
 	Params  json.RawMessage `json:"params,omitempty"`
 	ID      json.RawMessage `json:"id,omitempty"`
+
+	// Meta is a nexIO extension for out-of-band data like trace context.
+	// Plain JSON-RPC 2.0 peers simply ignore it.
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+// hasID reports whether the request expects a response.
+func (r Request) hasID() bool {
+	return r.ID != nil && string(r.ID) != "null"
+}
+
+// IsNotification reports whether no response is expected.
+func (r Request) IsNotification() bool {
+	return !r.hasID()
 }
 
 type Response struct {
@@ -56,6 +70,7 @@ var stdErrorMessages = map[int]string{
 	ErrCodeInternalError:   "Internal error",
 	ErrCodeUnauthorized:    "Unauthorized",
 	ErrCodeForbidden:       "Forbidden",
+	ErrCodeUnknownObject:   "Unknown object",
 }
 
 // Helper function for creating errors
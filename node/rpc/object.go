@@ -0,0 +1,323 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"reflect"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// Wire methods of remote object references.
+const (
+	MethodObjectCall    = "rpc.object.call"
+	MethodObjectRelease = "rpc.object.release"
+	MethodObjectRenew   = "rpc.object.renew"
+)
+
+// ErrCodeUnknownObject answers calls on released or expired objects.
+const ErrCodeUnknownObject = -32001
+
+// Ref is an opaque reference to an object exported by a Node. It travels
+// as {"$ref": "<id>", "leaseMs": 30000}; the peer turns it into a Proxy.
+type Ref struct {
+	ID      string `json:"$ref"`
+	LeaseMS int64  `json:"leaseMs,omitempty"`
+}
+
+type exportConfig struct {
+	lease time.Duration
+}
+
+// ExportOption configures Export.
+type ExportOption func(*exportConfig)
+
+// WithLease releases the object unless the peer renews it within d.
+// Proxies renew automatically at half the lease.
+func WithLease(d time.Duration) ExportOption {
+	return func(c *exportConfig) {
+		c.lease = d
+	}
+}
+
+type exported struct {
+	value    any
+	handlers map[string]HandlerFunc
+	refs     int
+	lease    time.Duration
+	timer    *time.Timer
+}
+
+type objectCall struct {
+	Ref    string          `json:"ref"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// Export makes the exported methods of obj callable by the peer through
+// the returned Ref. Methods follow the RegisterService rules and keep
+// their Go names ("Next"). Exporting the same pointer again returns the
+// same Ref and adds a reference; the object is dropped when all
+// references are released, its lease expires or the connection is lost.
+// Objects implementing io.Closer are closed when dropped; Close itself
+// is not callable remotely.
+//
+// Handlers usually export from their ctx:
+//
+//	node.Register("db.query", func(ctx context.Context, p json.RawMessage) (any, error) {
+//	    return rpc.NodeFromContext(ctx).Export(newCursor(p), rpc.WithLease(time.Minute))
+//	})
+func (node *Node) Export(obj any, opts ...ExportOption) (Ref, error) {
+	var cfg exportConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	node.objectMu.Lock()
+	defer node.objectMu.Unlock()
+
+	key, shared := objectKey(obj)
+	if shared {
+		if id, ok := node.objectIDs[key]; ok {
+			o := node.objects[id]
+			o.refs++
+			return node.ref(id, o), nil
+		}
+	}
+
+	// Close belongs to the object's lifetime, the peer uses Release.
+	handlers, err := reflectMethods("", obj, NameExact, "Close")
+	if err != nil {
+		return Ref{}, err
+	}
+
+	var raw [12]byte
+	_, _ = rand.Read(raw[:])
+	id := hex.EncodeToString(raw[:])
+	o := &exported{value: obj, handlers: handlers, refs: 1, lease: cfg.lease}
+	if o.lease > 0 {
+		o.timer = time.AfterFunc(o.lease, func() {
+			node.Log.With("ref", id).Debug("Object lease expired")
+			node.dropObject(id)
+		})
+	}
+	node.objects[id] = o
+	if shared {
+		node.objectIDs[key] = id
+	}
+	return node.ref(id, o), nil
+}
+
+// objectKey identifies obj for deduplication. Only pointers qualify:
+// values are independent copies, and a comparable struct type may still
+// hold an unhashable value in an interface field.
+func objectKey(obj any) (any, bool) {
+	if obj == nil || reflect.TypeOf(obj).Kind() != reflect.Pointer {
+		return nil, false
+	}
+	return obj, true
+}
+
+func (node *Node) ref(id string, o *exported) Ref {
+	return Ref{ID: id, LeaseMS: o.lease.Milliseconds()}
+}
+
+// Unexport drops the object behind ref regardless of its references.
+func (node *Node) Unexport(ref Ref) {
+	node.dropObject(ref.ID)
+}
+
+// Exported returns the number of objects currently exported.
+func (node *Node) Exported() int {
+	node.objectMu.Lock()
+	defer node.objectMu.Unlock()
+	return len(node.objects)
+}
+
+func (node *Node) dropObject(id string) {
+	node.objectMu.Lock()
+	o, ok := node.objects[id]
+	if ok {
+		delete(node.objects, id)
+		if key, shared := objectKey(o.value); shared && node.objectIDs[key] == id {
+			delete(node.objectIDs, key)
+		}
+	}
+	node.objectMu.Unlock()
+	if ok {
+		closeObject(o)
+	}
+}
+
+// releaseObjects drops all exports (connection lost).
+func (node *Node) releaseObjects() {
+	node.objectMu.Lock()
+	objects := node.objects
+	node.objects = make(map[string]*exported)
+	clear(node.objectIDs)
+	node.objectMu.Unlock()
+
+	for _, o := range objects {
+		closeObject(o)
+	}
+}
+
+func closeObject(o *exported) {
+	if o.timer != nil {
+		o.timer.Stop()
+	}
+	if c, ok := o.value.(io.Closer); ok {
+		_ = c.Close()
+	}
+}
+
+// objectHandler returns the built-in handler for the object methods.
+func (node *Node) objectHandler(method string) HandlerFunc {
+	switch method {
+	case MethodObjectCall:
+		return node.callObject
+	case MethodObjectRelease:
+		return node.releaseObject
+	case MethodObjectRenew:
+		return node.renewObject
+	}
+	return nil
+}
+
+func (node *Node) callObject(ctx context.Context, params json.RawMessage) (any, error) {
+	var call objectCall
+	if err := json.Unmarshal(params, &call); err != nil {
+		return nil, NewRPCError(ErrCodeInvalidParams, err.Error())
+	}
+	node.objectMu.Lock()
+	o, ok := node.objects[call.Ref]
+	node.objectMu.Unlock()
+	if !ok {
+		return nil, NewRPCError(ErrCodeUnknownObject, call.Ref)
+	}
+	h, ok := o.handlers[call.Method]
+	if !ok {
+		return nil, NewRPCError(ErrCodeMethodNotFound, call.Method)
+	}
+	return h(ctx, call.Params)
+}
+
+func (node *Node) releaseObject(ctx context.Context, params json.RawMessage) (any, error) {
+	var ref Ref
+	if err := json.Unmarshal(params, &ref); err != nil {
+		return nil, NewRPCError(ErrCodeInvalidParams, err.Error())
+	}
+	node.objectMu.Lock()
+	o, ok := node.objects[ref.ID]
+	if ok {
+		o.refs--
+	}
+	last := ok && o.refs <= 0
+	node.objectMu.Unlock()
+
+	if last {
+		node.dropObject(ref.ID)
+	}
+	return nil, nil
+}
+
+func (node *Node) renewObject(ctx context.Context, params json.RawMessage) (any, error) {
+	var ref Ref
+	if err := json.Unmarshal(params, &ref); err != nil {
+		return nil, NewRPCError(ErrCodeInvalidParams, err.Error())
+	}
+	node.objectMu.Lock()
+	defer node.objectMu.Unlock()
+	o, ok := node.objects[ref.ID]
+	if !ok {
+		return nil, NewRPCError(ErrCodeUnknownObject, ref.ID)
+	}
+	if o.timer != nil {
+		o.timer.Reset(o.lease)
+	}
+	return true, nil
+}
+
+// Proxy is the peer side of a Ref: it dispatches calls to the one
+// exported instance and keeps its lease alive until Release. A Proxy
+// that becomes unreachable without Release stops renewing, so the
+// exporter drops the object when the lease runs out; objects exported
+// without a lease stay until Release or disconnect.
+type Proxy struct {
+	*proxyState
+}
+
+// proxyState is shared with the renew goroutine, which must not
+// reference the Proxy itself or it would never become unreachable.
+type proxyState struct {
+	node *Node
+	ref  Ref
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// Proxy wraps ref, e.g. taken from a result with Bind[Ref].
+func (node *Node) Proxy(ref Ref) *Proxy {
+	p := &Proxy{&proxyState{node: node, ref: ref, stop: make(chan struct{})}}
+	if ref.LeaseMS > 0 {
+		go p.proxyState.renew(time.Duration(ref.LeaseMS) * time.Millisecond / 2)
+		runtime.SetFinalizer(p, func(p *Proxy) {
+			p.once.Do(func() { close(p.stop) })
+		})
+	}
+	return p
+}
+
+// Ref returns the reference, e.g. to hand it on to another call.
+func (p *Proxy) Ref() Ref {
+	return p.ref
+}
+
+// Call invokes method on the remote object.
+func (p *Proxy) Call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, NewRPCError(ErrCodeParseError, err.Error())
+	}
+	return p.node.Call(ctx, MethodObjectCall, objectCall{Ref: p.ref.ID, Method: method, Params: raw})
+}
+
+// Release gives up this reference; the exporter drops the object when
+// no references are left. Further calls are no-ops.
+func (p *Proxy) Release(ctx context.Context) error {
+	var err error
+	p.once.Do(func() {
+		close(p.stop)
+		err = p.node.Notify(ctx, MethodObjectRelease, p.ref)
+	})
+	return err
+}
+
+// renew keeps the lease alive until Release, until the Proxy is
+// collected or until the object is gone; exports do not survive a
+// connection loss either.
+func (p *proxyState) renew(every time.Duration) {
+	t := time.NewTicker(every)
+	defer t.Stop()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-t.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), every)
+		_, err := p.node.Call(ctx, MethodObjectRenew, p.ref)
+		cancel()
+		if rpcErr, ok := err.(*RPCError); ok && rpcErr.Code == ErrCodeUnknownObject || IsConnectionError(err) {
+			return
+		}
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type cursor struct {
+	rows   []string
+	pos    int
+	closed atomic.Bool
+}
+
+func (c *cursor) Next(ctx context.Context) (string, error) {
+	if c.pos >= len(c.rows) {
+		return "", errors.New("end of rows")
+	}
+	c.pos++
+	return c.rows[c.pos-1], nil
+}
+
+func (c *cursor) Close() error {
+	c.closed.Store(true)
+	return nil
+}
+
+func objectPair(t *testing.T, ctx context.Context) (server, client *Node, conn *transport.MemConnection) {
+	clientConn, serverConn := transport.NewMemPair()
+	server = NewNode(serverConn, nil, "", nil)
+	client = NewNode(clientConn, nil, "", nil)
+	go server.Listen(ctx)
+	go client.Listen(ctx)
+	return server, client, clientConn
+}
+
+func TestRemoteObject_ProxyAndRelease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, _ := objectPair(t, ctx)
+
+	var cursors []*cursor
+	server.Register("db.query", func(ctx context.Context, p json.RawMessage) (any, error) {
+		c := &cursor{rows: []string{"a", "b"}}
+		cursors = append(cursors, c)
+		return NodeFromContext(ctx).Export(c)
+	})
+
+	res, err := client.Call(ctx, "db.query", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := Bind[Ref](res)
+	if err != nil || ref.ID == "" {
+		t.Fatalf("bad ref %s: %v", res, err)
+	}
+
+	rows := client.Proxy(ref)
+	for _, want := range []string{`"a"`, `"b"`} {
+		got, err := rows.Call(ctx, "Next", nil)
+		if err != nil || string(got) != want {
+			t.Fatalf("Next: %s %v", got, err)
+		}
+	}
+
+	// A second query is a separate instance.
+	res2, _ := client.Call(ctx, "db.query", nil)
+	other, _ := Bind[Ref](res2)
+	if other.ID == ref.ID {
+		t.Fatal("instances share a ref")
+	}
+	if got, _ := client.Proxy(other).Call(ctx, "Next", nil); string(got) != `"a"` {
+		t.Fatalf("second cursor starts at %s", got)
+	}
+
+	if _, err := rows.Call(ctx, "Close", nil); err == nil {
+		t.Fatal("Close must not be callable remotely")
+	}
+
+	if err := rows.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "release", func() bool { return cursors[0].closed.Load() })
+	_, err = rows.Call(ctx, "Next", nil)
+	if rpcErr, ok := err.(*RPCError); !ok || rpcErr.Code != ErrCodeUnknownObject {
+		t.Fatalf("expected unknown object, got %v", err)
+	}
+	if server.Exported() != 1 {
+		t.Fatalf("expected the second cursor to stay exported, got %d", server.Exported())
+	}
+}
+
+func TestRemoteObject_RefCount(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, _ := objectPair(t, ctx)
+
+	shared := &cursor{rows: []string{"x"}}
+	r1, _ := server.Export(shared)
+	r2, _ := server.Export(shared)
+	if r1.ID != r2.ID {
+		t.Fatal("same object, different refs")
+	}
+
+	_ = client.Proxy(r1).Release(ctx)
+	time.Sleep(20 * time.Millisecond)
+	if shared.closed.Load() || server.Exported() != 1 {
+		t.Fatal("object dropped while referenced")
+	}
+	_ = client.Proxy(r2).Release(ctx)
+	waitFor(t, "last release", shared.closed.Load)
+}
+
+// valueCursor is comparable by type but may hold an unhashable value.
+type valueCursor struct {
+	Data any
+}
+
+func (v valueCursor) Len(ctx context.Context) (int, error) {
+	return len(v.Data.([]int)), nil
+}
+
+func TestRemoteObject_ExportValues(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, _ := objectPair(t, ctx)
+
+	r1, err := server.Export(valueCursor{Data: []int{1, 2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, _ := server.Export(valueCursor{Data: []int{1, 2}})
+	if r1.ID == r2.ID {
+		t.Fatal("values share a ref")
+	}
+	if got, err := client.Proxy(r1).Call(ctx, "Len", nil); err != nil || string(got) != "2" {
+		t.Fatalf("Len: %s %v", got, err)
+	}
+	_ = client.Proxy(r1).Release(ctx)
+	waitFor(t, "release", func() bool { return server.Exported() == 1 })
+}
+
+func TestRemoteObject_DoubleRelease(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, _ := objectPair(t, ctx)
+
+	shared := &cursor{rows: []string{"x"}}
+	r1, _ := server.Export(shared)
+	r2, _ := server.Export(shared)
+	first, second := client.Proxy(r1), client.Proxy(r2)
+
+	if err := first.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := first.Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got, err := second.Call(ctx, "Next", nil); err != nil || string(got) != `"x"` {
+		t.Fatalf("second proxy lost the object: %s %v", got, err)
+	}
+	_ = second.Release(ctx)
+	waitFor(t, "last release", shared.closed.Load)
+}
+
+func TestRemoteObject_DroppedProxyStopsRenewing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, _ := objectPair(t, ctx)
+
+	obj := &cursor{}
+	ref, _ := server.Export(obj, WithLease(40*time.Millisecond))
+	client.Proxy(ref)
+
+	waitFor(t, "lease expiry of a dropped proxy", func() bool {
+		runtime.GC()
+		return obj.closed.Load()
+	})
+}
+
+func TestRemoteObject_LeaseAndDisconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	server, client, conn := objectPair(t, ctx)
+
+	// Without a proxy nobody renews: the lease runs out.
+	orphan := &cursor{}
+	if _, err := server.Export(orphan, WithLease(30*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "lease expiry", orphan.closed.Load)
+
+	// A proxy keeps the lease alive.
+	kept := &cursor{rows: []string{"k"}}
+	ref, _ := server.Export(kept, WithLease(30*time.Millisecond))
+	p := client.Proxy(ref)
+	time.Sleep(100 * time.Millisecond)
+	if kept.closed.Load() {
+		t.Fatal("renewed object expired")
+	}
+	if got, err := p.Call(ctx, "Next", nil); err != nil || string(got) != `"k"` {
+		t.Fatalf("Next: %s %v", got, err)
+	}
+
+	// Objects do not outlive the connection.
+	conn.Close("gone")
+	waitFor(t, "release on disconnect", kept.closed.Load)
+	if server.Exported() != 0 {
+		t.Fatalf("%d objects left", server.Exported())
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// serve runs a Node with an echo and a blocking handler per accepted connection.
+func serve(ctx context.Context, p *transport.MemProvider, addr string, release <-chan struct{}) {
+	found := make(chan transport.Connection)
+	go p.Listen(ctx, addr, found)
+	go func() {
+		for {
+			select {
+			case conn := <-found:
+				node := NewNode(conn, nil, "", nil)
+				node.Register("echo", func(ctx context.Context, p json.RawMessage) (any, error) {
+					return p, nil
+				})
+				node.Register("block", func(ctx context.Context, p json.RawMessage) (any, error) {
+					<-release
+					return nil, nil
+				})
+				go node.Listen(ctx)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestReconnect_MemNetwork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := transport.NewMemNetwork()
+	release := make(chan struct{})
+	defer close(release)
+	serve(ctx, net.Provider("server"), "svc", release)
+
+	client := NewNode(nil, net.Provider("client"), "svc", nil)
+	client.SetReconnectBackoff(time.Millisecond, 5*time.Millisecond)
+	var connects atomic.Int32
+	client.OnConnect(func(ctx context.Context) { connects.Add(1) })
+	go client.Listen(ctx)
+
+	waitFor(t, "first connect", func() bool { return connects.Load() == 1 })
+	if _, err := client.Call(ctx, "echo", "a"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A dropped connection fails pending calls, then the Node redials.
+	errc := make(chan error, 1)
+	go func() {
+		_, err := client.Call(ctx, "block", nil)
+		errc <- err
+	}()
+	waitFor(t, "pending call", func() bool { return client.Pending() == 1 })
+	net.Disconnect("client")
+
+	select {
+	case err := <-errc:
+		if !IsConnectionError(err) {
+			t.Fatalf("expected connection error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("pending call not cleaned up")
+	}
+	waitFor(t, "reconnect", func() bool { return connects.Load() == 2 })
+	if res, err := client.Call(ctx, "echo", "b"); err != nil || string(res) != `"b"` {
+		t.Fatalf("call after reconnect: %s %v", res, err)
+	}
+
+	// During a partition dials fail and the Node keeps backing off.
+	net.Partition("client", "server")
+	waitFor(t, "disconnect", func() bool { return !client.Connected() })
+	time.Sleep(20 * time.Millisecond)
+	if client.Connected() || connects.Load() != 2 {
+		t.Fatal("dial through a partition must fail")
+	}
+	net.Heal("client", "server")
+	waitFor(t, "reconnect after heal", func() bool { return connects.Load() == 3 })
+	if _, err := client.Call(ctx, "echo", "c"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReconnect_CustomDialer(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := transport.NewMemNetwork()
+	serve(ctx, net.Provider("server"), "svc", nil)
+
+	// A proxying dialer maps logical names onto the real address.
+	mem := net.Provider("client")
+	var dials atomic.Int32
+	proxy := transport.DialerFunc(func(ctx context.Context, addr string) (transport.Connection, error) {
+		dials.Add(1)
+		return mem.Dial(ctx, strings.TrimPrefix(addr, "payment="))
+	})
+
+	client := NewNode(nil, proxy, "payment=svc", nil)
+	client.SetReconnectBackoff(time.Millisecond, time.Millisecond)
+	go client.Listen(ctx)
+
+	waitFor(t, "connect", client.Connected)
+	before := dials.Load()
+	net.Disconnect("")
+	waitFor(t, "redial", func() bool { return dials.Load() > before && client.Connected() })
+	if _, err := client.Call(ctx, "echo", 1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIsConnectionError(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{NewRPCError(ErrConnectionLostError, "lost"), true},
+		{NewRPCError(ErrCodeInternalError, "boom"), false},
+		{&SendError{Err: transport.ErrClosed}, true},
+		{fmt.Errorf("call: %w", &SendError{Err: transport.ErrClosed}), true},
+		{&SendError{Err: context.Canceled}, false},
+		{context.DeadlineExceeded, false},
+		{errors.New("decoding result"), false},
+	}
+	for _, c := range cases {
+		if got := IsConnectionError(c.err); got != c.want {
+			t.Errorf("IsConnectionError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+
+	// A failed send is reported as *SendError.
+	conn, _ := transport.NewMemPair()
+	conn.Close("test")
+	node := NewNode(conn, nil, "", nil)
+	var sendErr *SendError
+	if _, err := node.Call(context.Background(), "echo", nil); !errors.As(err, &sendErr) {
+		t.Errorf("Expected *SendError, got %v", err)
+	}
+}
@@ -0,0 +1,461 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema used to validate params:
+// type, properties, required, items, enum, minimum/maximum,
+// minLength/maxLength, minItems/maxItems, pattern and additionalProperties.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+
+	// order of the properties for positional (array) params,
+	// only known for schemas derived from Go structs.
+	order   []string
+	pattern *regexp.Regexp
+}
+
+// ParseSchema reads a JSON Schema document.
+func ParseSchema(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// SchemaFor derives a Schema from T. Struct fields use their json names;
+// the `validate` tag adds constraints:
+//
+//	required        field must be present
+//	min=N, max=N    number range, string length or item count
+//	len=N           exact string length or item count
+//	oneof=a b c     allowed values
+//	pattern=RE      regular expression for strings; must come last,
+//	                the rest of the tag (commas included) is RE
+//
+// An invalid pattern is an error.
+func SchemaFor[T any]() (*Schema, error) {
+	s := schemaOf(reflect.TypeOf((*T)(nil)).Elem(), make(map[reflect.Type]bool))
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]bool) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: schemaOf(t.Elem(), visiting)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOf(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return &Schema{Type: "object"} // recursive type, stop here
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		for _, f := range positionalFields(t) {
+			sf := t.FieldByIndex(f.index)
+			fs := schemaOf(sf.Type, visiting)
+			if applyTag(fs, sf.Tag.Get("validate")) {
+				s.Required = append(s.Required, f.name)
+			}
+			s.Properties[f.name] = fs
+			s.order = append(s.order, f.name)
+		}
+		return s
+	}
+	return &Schema{} // interface{} and friends: anything goes
+}
+
+// applyTag adds the validate tag constraints to s and reports "required".
+func applyTag(s *Schema, tag string) (required bool) {
+	if tag == "" {
+		return false
+	}
+	for tag != "" {
+		var rule string
+		if strings.HasPrefix(strings.TrimSpace(tag), "pattern=") {
+			// The pattern may contain commas ("^\d{1,3}$").
+			rule, tag = strings.TrimSpace(tag), ""
+		} else {
+			rule, tag, _ = strings.Cut(tag, ",")
+		}
+		key, val, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		switch key {
+		case "required":
+			required = true
+		case "min", "max", "len":
+			n, err := strconv.ParseFloat(val, 64)
+			if err != nil {
+				continue
+			}
+			setBound(s, key, n)
+		case "oneof":
+			for _, v := range strings.Fields(val) {
+				s.Enum = append(s.Enum, enumValue(s.Type, v))
+			}
+		case "pattern":
+			s.Pattern = val
+		}
+	}
+	return required
+}
+
+func setBound(s *Schema, key string, n float64) {
+	i := int(n)
+	switch s.Type {
+	case "string":
+		if key != "max" {
+			s.MinLength = &i
+		}
+		if key != "min" {
+			s.MaxLength = &i
+		}
+	case "array":
+		if key != "max" {
+			s.MinItems = &i
+		}
+		if key != "min" {
+			s.MaxItems = &i
+		}
+	default:
+		if key != "max" {
+			s.Minimum = &n
+		}
+		if key != "min" {
+			s.Maximum = &n
+		}
+	}
+}
+
+func enumValue(typ, v string) any {
+	if typ == "integer" || typ == "number" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+// compile prepares regular expressions for the whole schema tree.
+func (s *Schema) compile() error {
+	if s == nil {
+		return nil
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("schema pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = re
+	}
+	for _, p := range s.Properties {
+		if err := p.compile(); err != nil {
+			return err
+		}
+	}
+	if err := s.Items.compile(); err != nil {
+		return err
+	}
+	return s.AdditionalProperties.compile()
+}
+
+// Validate checks params against the schema and returns all violations.
+// Positional params are mapped onto properties if the order is known.
+func (s *Schema) Validate(params json.RawMessage) []ParamError {
+	params = bytes.TrimSpace(params)
+	if len(params) == 0 {
+		params = json.RawMessage("null")
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return []ParamError{{Field: "params", Reason: err.Error()}}
+	}
+
+	if arr, ok := v.([]any); ok && s.Type == "object" && len(s.order) > 0 {
+		if len(arr) > len(s.order) {
+			return []ParamError{{Field: "params", Reason: fmt.Sprintf("too many params: got %d, want at most %d", len(arr), len(s.order))}}
+		}
+		obj := make(map[string]any, len(arr))
+		for i, e := range arr {
+			obj[s.order[i]] = e
+		}
+		v = obj
+	} else if ok && len(arr) == 1 && s.Type != "array" && s.Type != "" {
+		// A single positional value, as Bind accepts it.
+		v = arr[0]
+	}
+
+	var errs []ParamError
+	s.validate("", v, &errs)
+	return errs
+}
+
+// check is Validate for an optional schema.
+func (s *Schema) check(params json.RawMessage) []ParamError {
+	if s == nil {
+		return nil
+	}
+	return s.Validate(params)
+}
+
+func (s *Schema) validate(path string, v any, errs *[]ParamError) {
+	fail := func(format string, args ...any) {
+		field := path
+		if field == "" {
+			field = "params"
+		}
+		*errs = append(*errs, ParamError{Field: field, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	if s.Type != "" && !hasType(v, s.Type) {
+		fail("expected %s, got %s", s.Type, typeName(v))
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(v, s.Enum) {
+		fail("must be one of %v", s.Enum)
+	}
+
+	switch val := v.(type) {
+	case string:
+		n := utf8.RuneCountInString(val)
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("length must be >= %d", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("length must be <= %d", *s.MaxLength)
+		}
+		if s.pattern != nil && !s.pattern.MatchString(val) {
+			fail("must match %q", s.Pattern)
+		}
+	case json.Number:
+		f, _ := val.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be >= %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be <= %v", *s.Maximum)
+		}
+	case []any:
+		if s.MinItems != nil && len(val) < *s.MinItems {
+			fail("must have >= %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(val) > *s.MaxItems {
+			fail("must have <= %d items", *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, e := range val {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), e, errs)
+			}
+		}
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := val[name]; !ok {
+				*errs = append(*errs, ParamError{Field: joinPath(path, name), Reason: "is required"})
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if ps, ok := s.Properties[k]; ok {
+				ps.validate(joinPath(path, k), val[k], errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(joinPath(path, k), val[k], errs)
+			}
+		}
+	}
+}
+
+func hasType(v any, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	}
+	return true
+}
+
+func typeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func inEnum(v any, enum []any) bool {
+	for _, e := range enum {
+		if n, ok := v.(json.Number); ok {
+			f, _ := n.Float64()
+			if ef, ok := e.(float64); ok && ef == f {
+				return true
+			}
+			continue
+		}
+		if reflect.DeepEqual(v, e) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// RegisterWithSchema registers h and validates params against schema
+// before h is called. Violations are answered with ErrCodeInvalidParams.
+// An invalid pattern in schema is an error and nothing is registered.
+func (node *Node) RegisterWithSchema(method string, schema *Schema, h HandlerFunc) error {
+	if err := schema.compile(); err != nil {
+		return err
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.handlers[method] = h
+	node.schemas[method] = schema
+	return nil
+}
+
+// SetSchema attaches (or with nil removes) a params schema for method.
+func (node *Node) SetSchema(method string, schema *Schema) error {
+	if err := schema.compile(); err != nil {
+		return err
+	}
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	if schema == nil {
+		delete(node.schemas, method)
+		return nil
+	}
+	node.schemas[method] = schema
+	return nil
+}
+
+// MethodInfo describes a registered method in the introspection output.
+type MethodInfo struct {
+	Name   string  `json:"name"`
+	Params *Schema `json:"params,omitempty"`
+}
+
+// MethodDiscover is the introspection method name ("rpc." is reserved
+// for protocol-internal methods by JSON-RPC 2.0).
+const MethodDiscover = "rpc.discover"
+
+// Methods lists the registered methods with their params schemas.
+func (node *Node) Methods() []MethodInfo {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	out := make([]MethodInfo, 0, len(node.handlers))
+	for name := range node.handlers {
+		out = append(out, MethodInfo{Name: name, Params: node.schemas[name]})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// EnableIntrospection registers "rpc.discover", which answers with Methods().
+// Peers can use it to check payloads before sending.
+func (node *Node) EnableIntrospection() {
+	node.Register(MethodDiscover, func(ctx context.Context, params json.RawMessage) (any, error) {
+		return node.Methods(), nil
+	})
+}
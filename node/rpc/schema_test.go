@@ -0,0 +1,150 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type orderParams struct {
+	OrderID  string   `json:"orderId" validate:"required,pattern=^ORD-[0-9]+$"`
+	Amount   int      `json:"amount" validate:"required,min=1,max=1000"`
+	Currency string   `json:"currency" validate:"oneof=EUR USD"`
+	Tags     []string `json:"tags" validate:"max=2"`
+}
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := SchemaFor[orderParams]()
+	if err != nil {
+		t.Fatalf("SchemaFor failed: %v", err)
+	}
+
+	if errs := s.Validate(json.RawMessage(`{"orderId":"ORD-1","amount":10,"currency":"EUR"}`)); len(errs) != 0 {
+		t.Errorf("Expected valid params, got %+v", errs)
+	}
+	errs := s.Validate(json.RawMessage(`["ORD-2",5]`))
+	if len(errs) != 0 {
+		t.Errorf("Expected valid positional params, got %+v", errs)
+	}
+
+	errs = s.Validate(json.RawMessage(`{"amount":0,"currency":"CHF","tags":["a","b","c"]}`))
+	fields := make(map[string]bool)
+	for _, e := range errs {
+		fields[e.Field] = true
+	}
+	for _, f := range []string{"orderId", "amount", "currency", "tags"} {
+		if !fields[f] {
+			t.Errorf("Expected violation for %s, got %+v", f, errs)
+		}
+	}
+}
+
+func TestSchemaPatternWithComma(t *testing.T) {
+	type code struct {
+		Code string `json:"code" validate:"required,pattern=^\\d{1,3}$"`
+	}
+	s, err := SchemaFor[code]()
+	if err != nil {
+		t.Fatalf("SchemaFor failed: %v", err)
+	}
+	if p := s.Properties["code"].Pattern; p != `^\d{1,3}$` {
+		t.Fatalf("Expected the whole pattern, got %q", p)
+	}
+	if errs := s.Validate(json.RawMessage(`{"code":"12"}`)); len(errs) != 0 {
+		t.Errorf("Expected valid code, got %+v", errs)
+	}
+	if errs := s.Validate(json.RawMessage(`{"code":"1234"}`)); len(errs) != 1 {
+		t.Errorf("Expected pattern violation, got %+v", errs)
+	}
+	if errs := s.Validate(json.RawMessage(`{}`)); len(errs) != 1 {
+		t.Errorf("Expected required violation, got %+v", errs)
+	}
+
+	type broken struct {
+		Code string `json:"code" validate:"pattern=[a-"`
+	}
+	if _, err := SchemaFor[broken](); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+	node := NewNode(nil, nil, "", nil)
+	bad := &Schema{Type: "string", Pattern: "(x"}
+	if err := node.RegisterWithSchema("m", bad, nil); err == nil || len(node.Methods()) != 0 {
+		t.Errorf("Expected RegisterWithSchema to fail, got %v", err)
+	}
+}
+
+func TestSchemaSingleValue(t *testing.T) {
+	// Validate accepts a single positional value exactly when Bind does.
+	s := &Schema{Type: "string"}
+	for _, params := range []string{`"xy"`, `["xy"]`, `["xy","z"]`, `[]`, `[1]`} {
+		_, err := Bind[string](json.RawMessage(params))
+		bound := err == nil
+		valid := len(s.Validate(json.RawMessage(params))) == 0
+		if bound != valid {
+			t.Errorf("%s: Bind ok=%v, Validate ok=%v", params, bound, valid)
+		}
+	}
+
+	minLen := 2
+	s.MinLength = &minLen
+	if errs := s.Validate(json.RawMessage(`["x"]`)); len(errs) != 1 {
+		t.Errorf("Expected the unwrapped value to be checked, got %+v", errs)
+	}
+}
+
+func TestParseSchema(t *testing.T) {
+	s, err := ParseSchema([]byte(`{"type":"object","required":["name"],"properties":{"name":{"type":"string","minLength":2}}}`))
+	if err != nil {
+		t.Fatalf("ParseSchema failed: %v", err)
+	}
+	if errs := s.Validate(json.RawMessage(`{"name":"x"}`)); len(errs) != 1 || errs[0].Field != "name" {
+		t.Errorf("Expected minLength violation, got %+v", errs)
+	}
+}
+
+func TestRegisterWithSchema(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	called := false
+	schema, err := SchemaFor[orderParams]()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := serverNode.RegisterWithSchema("order.create", schema, func(ctx context.Context, p json.RawMessage) (any, error) {
+		called = true
+		return "ok", nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	serverNode.EnableIntrospection()
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	_, err = clientNode.Call(ctx, "order.create", map[string]any{"amount": -1})
+	var rpcErr *RPCError
+	if !errors.As(err, &rpcErr) || rpcErr.Code != ErrCodeInvalidParams {
+		t.Fatalf("Expected invalid params, got %v", err)
+	}
+	if called {
+		t.Error("Handler must not run for invalid params")
+	}
+
+	res, err := clientNode.Call(ctx, MethodDiscover, nil)
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+	var methods []MethodInfo
+	json.Unmarshal(res, &methods)
+	if len(methods) != 2 || methods[0].Name != "order.create" || methods[0].Params == nil {
+		t.Errorf("Unexpected introspection output %s", res)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+type serviceConfig struct {
+	naming NamingFunc
+}
+
+// ServiceOption configures RegisterService.
+type ServiceOption func(*serviceConfig)
+
+// WithNaming sets how Go method names are mapped onto wire names.
+// The default is NameExact ("payment.Process").
+func WithNaming(fn NamingFunc) ServiceOption {
+	return func(c *serviceConfig) {
+		c.naming = fn
+	}
+}
+
+// RegisterService registers every exported method of impl as
+// "<namespace>.<Method>". Methods must have the form
+//
+//	func(ctx context.Context[, p P]) ([R, ]error)
+//
+// Params are decoded into P (see Bind) and the result is encoded automatically.
+// If any method has a different signature nothing is registered and
+// an error listing all offending methods is returned.
+func (node *Node) RegisterService(namespace string, impl any, opts ...ServiceOption) error {
+	cfg := serviceConfig{naming: NameExact}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	handlers, err := reflectMethods(namespace, impl, cfg.naming)
+	if err != nil {
+		return err
+	}
+
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	for name, h := range handlers {
+		node.handlers[name] = h
+	}
+	return nil
+}
+
+// reflectMethods builds one HandlerFunc per exported method of impl,
+// except the ones named in skip.
+func reflectMethods(namespace string, impl any, naming NamingFunc, skip ...string) (map[string]HandlerFunc, error) {
+	v := reflect.ValueOf(impl)
+	if !v.IsValid() {
+		return nil, fmt.Errorf("service %q: impl is nil", namespace)
+	}
+	t := v.Type()
+	if t.NumMethod() == 0 {
+		return nil, fmt.Errorf("service %q: %s has no exported methods", namespace, t)
+	}
+
+	handlers := make(map[string]HandlerFunc)
+	var invalid []string
+	for i := 0; i < t.NumMethod(); i++ {
+		m := t.Method(i)
+		if slices.Contains(skip, m.Name) {
+			continue
+		}
+		fn := v.Method(i)
+		if err := checkSignature(fn.Type()); err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", m.Name, err))
+			continue
+		}
+		handlers[naming(namespace, m.Name)] = reflectHandler(fn)
+	}
+
+	if len(invalid) > 0 {
+		return nil, fmt.Errorf("service %q (%s): invalid methods: %s", namespace, t, strings.Join(invalid, "; "))
+	}
+	return handlers, nil
+}
+
+func checkSignature(ft reflect.Type) error {
+	if ft.NumIn() < 1 || ft.NumIn() > 2 || ft.In(0) != contextType {
+		return fmt.Errorf("want func(context.Context[, P]), got %s", ft)
+	}
+	if ft.NumOut() < 1 || ft.NumOut() > 2 || ft.Out(ft.NumOut()-1) != errorType {
+		return fmt.Errorf("want ([R, ]error) results, got %s", ft)
+	}
+	return nil
+}
+
+func reflectHandler(fn reflect.Value) HandlerFunc {
+	ft := fn.Type()
+	return func(ctx context.Context, params json.RawMessage) (any, error) {
+		args := []reflect.Value{reflect.ValueOf(ctx)}
+		if ft.NumIn() == 2 {
+			p := reflect.New(ft.In(1)).Elem()
+			if err := bindInto(params, p); err != nil {
+				return nil, err
+			}
+			args = append(args, p)
+		}
+
+		out := fn.Call(args)
+		if errV := out[len(out)-1]; !errV.IsNil() {
+			return nil, errV.Interface().(error)
+		}
+		if len(out) == 2 {
+			return out[0].Interface(), nil
+		}
+		return nil, nil
+	}
+}
@@ -0,0 +1,90 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type receipt struct {
+	OrderID string `json:"orderId"`
+	Amount  int    `json:"amount"`
+}
+
+type paymentService struct{}
+
+func (paymentService) Process(ctx context.Context, orderID string) (receipt, error) {
+	return receipt{OrderID: orderID, Amount: 42}, nil
+}
+
+func (paymentService) Fail(ctx context.Context, orderID string) (string, error) {
+	return "", errors.New("declined")
+}
+
+type brokenService struct{}
+
+func (brokenService) Process(orderID string) string { return orderID }
+
+func TestRegisterService(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	serverNode := NewNode(serverConn, nil, "", nil)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	if err := serverNode.RegisterService("payment", paymentService{}, WithNaming(NameLowerCamel)); err != nil {
+		t.Fatalf("RegisterService failed: %v", err)
+	}
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	res, err := clientNode.Call(ctx, "payment.process", "A-1")
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if string(res) != `{"orderId":"A-1","amount":42}` {
+		t.Errorf("Unexpected result %s", res)
+	}
+
+	if _, err := clientNode.Call(ctx, "payment.process", 17); err == nil || !strings.Contains(err.Error(), "-32602") {
+		t.Errorf("Expected invalid params error, got %v", err)
+	}
+	if _, err := clientNode.Call(ctx, "payment.fail", "A-2"); err == nil {
+		t.Error("Expected error from payment.fail")
+	}
+}
+
+func TestRegisterServiceInvalidSignature(t *testing.T) {
+	node := NewNode(nil, nil, "", nil)
+	err := node.RegisterService("broken", brokenService{})
+	if err == nil || !strings.Contains(err.Error(), "Process") {
+		t.Fatalf("Expected signature error for Process, got %v", err)
+	}
+	if _, ok := node.handlers["broken.Process"]; ok {
+		t.Error("Nothing should be registered for an invalid service")
+	}
+}
+
+func TestNaming(t *testing.T) {
+	cases := []struct {
+		fn   NamingFunc
+		in   string
+		want string
+	}{
+		{NameExact, "ProcessOrder", "svc.ProcessOrder"},
+		{NameLowerCamel, "ProcessOrder", "svc.processOrder"},
+		{NameLowerCamel, "URLFor", "svc.urlFor"},
+		{NameSnake, "ProcessOrder", "svc.process_order"},
+		{NameSnake, "GetHTTPStatus", "svc.get_http_status"},
+	}
+	for _, c := range cases {
+		if got := c.fn("svc", c.in); got != c.want {
+			t.Errorf("%s: expected %s, got %s", c.in, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package rpc
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Meta keys used for trace propagation (W3C Trace Context).
+const (
+	MetaTraceparent = "traceparent"
+	MetaTracestate  = "tracestate"
+)
+
+// TraceContext is the W3C trace context that travels with a Call.
+// TraceID is 32 hex characters, SpanID 16 hex characters.
+type TraceContext struct {
+	TraceID string
+	SpanID  string
+	Flags   byte
+	State   string // opaque tracestate, passed through unchanged
+}
+
+// NewTraceContext starts a new trace with a random trace and span id.
+func NewTraceContext() TraceContext {
+	return TraceContext{
+		TraceID: randomHex(16),
+		SpanID:  randomHex(8),
+		Flags:   0x01, // sampled
+	}
+}
+
+// Traceparent renders the context in the W3C "traceparent" format.
+func (tc TraceContext) Traceparent() string {
+	return fmt.Sprintf("00-%s-%s-%02x", tc.TraceID, tc.SpanID, tc.Flags)
+}
+
+// IsValid reports whether trace and span id are set.
+func (tc TraceContext) IsValid() bool {
+	return tc.TraceID != "" && tc.SpanID != ""
+}
+
+// ParseTraceparent parses a W3C "traceparent" header value.
+// Version 00 has exactly four fields; later versions may append more.
+func ParseTraceparent(s string) (TraceContext, error) {
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if parts[0] == "00" && len(parts) != 4 {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	if !isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	// all-zero ids are invalid per spec
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return TraceContext{}, fmt.Errorf("invalid traceparent %q", s)
+	}
+	flags, _ := hex.DecodeString(parts[3])
+	return TraceContext{
+		TraceID: parts[1],
+		SpanID:  parts[2],
+		Flags:   flags[0],
+	}, nil
+}
+
+type traceKey struct{}
+type logKey struct{}
+
+// ContextWithTrace returns a copy of ctx carrying tc.
+// Calls made with that ctx continue the trace.
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, tc)
+}
+
+// TraceFromContext returns the trace context stored in ctx, if any.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceKey{}).(TraceContext)
+	return tc, ok && tc.IsValid()
+}
+
+// LogFromContext returns the LogSink a Node attached to a handler ctx.
+// It already carries the trace_id and span_id fields of the request.
+// Outside of a handler a SilentLogger is returned.
+func LogFromContext(ctx context.Context) transport.LogSink {
+	if l, ok := ctx.Value(logKey{}).(transport.LogSink); ok {
+		return l
+	}
+	return &transport.SilentLogger{}
+}
+
+// SpanKind tells whether a span was emitted by the calling or the called side.
+type SpanKind int
+
+const (
+	SpanClient SpanKind = iota
+	SpanServer
+)
+
+func (k SpanKind) String() string {
+	if k == SpanServer {
+		return "server"
+	}
+	return "client"
+}
+
+// Span describes a single RPC as seen by one Node.
+// It is handed to the Tracer and can be exported to any tracing backend.
+type Span struct {
+	Kind         SpanKind
+	Method       string
+	Notification bool
+	TraceID      string
+	SpanID       string
+	ParentSpanID string
+	State        string
+	Start        time.Time
+	End          time.Time
+	Err          error
+}
+
+// Tracer receives span start and end events.
+// StartSpan may return a derived ctx (e.g. to attach an SDK span);
+// the same ctx is later passed to EndSpan.
+type Tracer interface {
+	StartSpan(ctx context.Context, span *Span) context.Context
+	EndSpan(ctx context.Context, span *Span)
+}
+
+// SetTracer installs the span hooks. nil disables tracing hooks,
+// trace context is propagated either way.
+func (node *Node) SetTracer(t Tracer) {
+	node.mu.Lock()
+	defer node.mu.Unlock()
+	node.tracer = t
+}
+
+// startClientSpan derives the outgoing trace context from ctx and
+// writes it into meta.
+func (node *Node) startClientSpan(ctx context.Context, method string, notification bool, meta map[string]string) (context.Context, *Span) {
+	parent, ok := TraceFromContext(ctx)
+	if !ok {
+		parent = NewTraceContext()
+		parent.SpanID = ""
+	}
+
+	span := &Span{
+		Kind:         SpanClient,
+		Method:       method,
+		Notification: notification,
+		TraceID:      parent.TraceID,
+		SpanID:       randomHex(8),
+		ParentSpanID: parent.SpanID,
+		State:        parent.State,
+		Start:        time.Now(),
+	}
+
+	out := TraceContext{TraceID: span.TraceID, SpanID: span.SpanID, Flags: parent.Flags, State: parent.State}
+	meta[MetaTraceparent] = out.Traceparent()
+	if out.State != "" {
+		meta[MetaTracestate] = out.State
+	}
+
+	if t := node.getTracer(); t != nil {
+		ctx = t.StartSpan(ctx, span)
+	}
+	return ctx, span
+}
+
+// startServerSpan restores the caller's trace context from the request
+// metadata and prepares the handler ctx (trace + enriched LogSink).
+func (node *Node) startServerSpan(ctx context.Context, req Request) (context.Context, *Span) {
+	remote, err := ParseTraceparent(req.Meta[MetaTraceparent])
+	if err != nil {
+		// tracestate is meaningless without a valid traceparent.
+		remote = NewTraceContext()
+		remote.SpanID = ""
+	} else {
+		remote.State = req.Meta[MetaTracestate]
+	}
+
+	span := &Span{
+		Kind:         SpanServer,
+		Method:       req.Method,
+		Notification: !req.hasID(),
+		TraceID:      remote.TraceID,
+		SpanID:       randomHex(8),
+		ParentSpanID: remote.SpanID,
+		State:        remote.State,
+		Start:        time.Now(),
+	}
+
+	ctx = ContextWithTrace(ctx, TraceContext{TraceID: span.TraceID, SpanID: span.SpanID, Flags: remote.Flags, State: span.State})
+	ctx = context.WithValue(ctx, logKey{}, node.Log.With("trace_id", span.TraceID).With("span_id", span.SpanID))
+
+	if t := node.getTracer(); t != nil {
+		ctx = t.StartSpan(ctx, span)
+	}
+	return ctx, span
+}
+
+func (node *Node) endSpan(ctx context.Context, span *Span, err error) {
+	span.End = time.Now()
+	span.Err = err
+	if t := node.getTracer(); t != nil {
+		t.EndSpan(ctx, span)
+	}
+}
+
+func (node *Node) getTracer() Tracer {
+	node.mu.RLock()
+	defer node.mu.RUnlock()
+	return node.tracer
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
@@ -0,0 +1,151 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	ended []Span
+}
+
+func (r *recordingTracer) StartSpan(ctx context.Context, s *Span) context.Context { return ctx }
+func (r *recordingTracer) EndSpan(ctx context.Context, s *Span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ended = append(r.ended, *s)
+}
+
+// fieldSink records the fields every message was logged with.
+type fieldSink struct {
+	fields map[string]any
+	mu     *sync.Mutex
+	logged map[string]map[string]any
+}
+
+func newFieldSink() *fieldSink {
+	return &fieldSink{mu: &sync.Mutex{}, logged: make(map[string]map[string]any)}
+}
+
+func (f *fieldSink) log(msg string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.logged[msg] = f.fields
+}
+
+func (f *fieldSink) Debug(msg string) { f.log(msg) }
+func (f *fieldSink) Info(msg string)  { f.log(msg) }
+func (f *fieldSink) Warn(msg string)  { f.log(msg) }
+func (f *fieldSink) Error(msg string) { f.log(msg) }
+func (f *fieldSink) With(key string, value any) transport.LogSink {
+	fields := map[string]any{key: value}
+	for k, v := range f.fields {
+		fields[k] = v
+	}
+	return &fieldSink{fields: fields, mu: f.mu, logged: f.logged}
+}
+
+func TestTraceparentRoundTrip(t *testing.T) {
+	tc := NewTraceContext()
+	parsed, err := ParseTraceparent(tc.Traceparent())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parsed.TraceID != tc.TraceID || parsed.SpanID != tc.SpanID || parsed.Flags != tc.Flags {
+		t.Errorf("Expected %+v, got %+v", tc, parsed)
+	}
+
+	invalid := []string{
+		"",
+		"00-abc-def-01",
+		"00-00000000000000000000000000000000-0000000000000001-01",
+		tc.Traceparent() + "-extra",
+	}
+	for _, bad := range invalid {
+		if _, err := ParseTraceparent(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestTracePropagation(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clientConn, serverConn := transport.NewMemPair()
+	sink := newFieldSink()
+	serverNode := NewNode(serverConn, nil, "", sink)
+	clientNode := NewNode(clientConn, nil, "", nil)
+
+	serverTracer := &recordingTracer{}
+	clientTracer := &recordingTracer{}
+	serverNode.SetTracer(serverTracer)
+	clientNode.SetTracer(clientTracer)
+
+	seen := make(chan TraceContext, 1)
+	serverNode.Register("trace", func(ctx context.Context, p json.RawMessage) (any, error) {
+		tc, _ := TraceFromContext(ctx)
+		LogFromContext(ctx).Info("handling")
+		seen <- tc
+		return nil, nil
+	})
+
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	root := NewTraceContext()
+	if _, err := clientNode.Call(ContextWithTrace(ctx, root), "trace", nil); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	got := <-seen
+	if got.TraceID != root.TraceID {
+		t.Errorf("Expected trace id %s, got %s", root.TraceID, got.TraceID)
+	}
+	sink.mu.Lock()
+	fields := sink.logged["handling"]
+	sink.mu.Unlock()
+	if fields["trace_id"] != root.TraceID || fields["span_id"] != got.SpanID {
+		t.Errorf("Expected handler log with trace_id %s and span_id %s, got %v", root.TraceID, got.SpanID, fields)
+	}
+
+	clientTracer.mu.Lock()
+	defer clientTracer.mu.Unlock()
+	if len(clientTracer.ended) != 1 || clientTracer.ended[0].ParentSpanID != root.SpanID {
+		t.Fatalf("Expected one client span with parent %s, got %+v", root.SpanID, clientTracer.ended)
+	}
+
+	// The server span is ended before the response is sent.
+	serverTracer.mu.Lock()
+	defer serverTracer.mu.Unlock()
+	if len(serverTracer.ended) != 1 || serverTracer.ended[0].ParentSpanID != clientTracer.ended[0].SpanID {
+		t.Errorf("Expected server span as child of client span, got %+v", serverTracer.ended)
+	}
+}
+
+func TestServerSpanTracestate(t *testing.T) {
+	node := NewNode(nil, nil, "", nil)
+	parent := NewTraceContext()
+
+	_, span := node.startServerSpan(context.Background(), Request{Method: "m", Meta: map[string]string{
+		MetaTraceparent: parent.Traceparent(),
+		MetaTracestate:  "vendor=1",
+	}})
+	if span.TraceID != parent.TraceID || span.State != "vendor=1" {
+		t.Errorf("Expected trace %s with state, got %+v", parent.TraceID, span)
+	}
+
+	// An invalid traceparent starts a new trace and drops tracestate.
+	_, span = node.startServerSpan(context.Background(), Request{Method: "m", Meta: map[string]string{
+		MetaTraceparent: "00-bogus",
+		MetaTracestate:  "vendor=1",
+	}})
+	if span.TraceID == "" || span.ParentSpanID != "" || span.State != "" {
+		t.Errorf("Expected a fresh trace without state, got %+v", span)
+	}
+}
@@ -0,0 +1,155 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"sync"
+)
+
+// Compression selects how a WSProvider compresses its connections.
+// It is negotiated during the websocket handshake: if the peer does not
+// support the same mode, the connection stays uncompressed.
+type Compression int
+
+const (
+	CompressionNone Compression = iota
+	// CompressionGzip compresses single messages at application level.
+	// Compression ratios are available via CompressedConnection.Stats.
+	CompressionGzip
+	// CompressionDeflate uses websocket permessage-deflate (RFC 7692).
+	// It is handled by the websocket library; WSConnection.Stats measures
+	// the wire side on the network connection.
+	CompressionDeflate
+)
+
+// HeaderCompression carries the offered and accepted app-level codec.
+const HeaderCompression = "X-Nexio-Compression"
+
+// DefaultCompressionThreshold is the minimum message size worth compressing.
+const DefaultCompressionThreshold = 1024
+
+// DefaultReadLimit is the largest message accepted by default (the
+// websocket library's own default).
+const DefaultReadLimit = 32768
+
+// ErrMessageTooLarge is returned by Receive when a message inflates
+// beyond the read limit.
+var ErrMessageTooLarge = errors.New("message exceeds read limit")
+
+// ConnStats counts the traffic of a connection. Raw is the size before
+// compression (outgoing) or after decompression (incoming), Wire what
+// actually went over the connection.
+type ConnStats struct {
+	MessagesSent     uint64
+	MessagesReceived uint64
+	RawBytesSent     uint64
+	WireBytesSent    uint64
+	RawBytesReceived uint64
+	WireBytesRecv    uint64
+}
+
+// Ratio returns wire/raw for all traffic (1 = no gain, 0.25 = 75% saved).
+func (s ConnStats) Ratio() float64 {
+	raw := s.RawBytesSent + s.RawBytesReceived
+	if raw == 0 {
+		return 1
+	}
+	return float64(s.WireBytesSent+s.WireBytesRecv) / float64(raw)
+}
+
+// StatsReporter is implemented by connections that keep ConnStats.
+type StatsReporter interface {
+	Stats() ConnStats
+}
+
+// gzip output starts with this magic; it doubles as binary frame marker.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// CompressedConnection gzips outgoing messages of at least Threshold
+// bytes (if that actually makes them smaller) and transparently inflates
+// incoming gzip messages. Both peers must have agreed on it.
+//
+// Inflated messages larger than MaxSize (0 = DefaultReadLimit) fail
+// with ErrMessageTooLarge, so small frames cannot expand without bound.
+type CompressedConnection struct {
+	Connection
+	Threshold int
+	MaxSize   int64
+
+	mu    sync.Mutex
+	stats ConnStats
+}
+
+func NewCompressedConnection(conn Connection, threshold int) *CompressedConnection {
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+	return &CompressedConnection{Connection: conn, Threshold: threshold}
+}
+
+func (c *CompressedConnection) Send(ctx context.Context, data []byte) error {
+	out := data
+	if len(data) >= c.Threshold {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(data); err == nil && zw.Close() == nil && buf.Len() < len(data) {
+			out = buf.Bytes()
+		}
+	}
+
+	if err := c.Connection.Send(ctx, out); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.stats.MessagesSent++
+	c.stats.RawBytesSent += uint64(len(data))
+	c.stats.WireBytesSent += uint64(len(out))
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *CompressedConnection) Receive(ctx context.Context) ([]byte, error) {
+	data, err := c.Connection.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wire := len(data)
+	if bytes.HasPrefix(data, gzipMagic) {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		limit := c.MaxSize
+		if limit <= 0 {
+			limit = DefaultReadLimit
+		}
+		if data, err = io.ReadAll(io.LimitReader(zr, limit+1)); err != nil {
+			return nil, err
+		}
+		if int64(len(data)) > limit {
+			return nil, ErrMessageTooLarge
+		}
+	}
+
+	c.mu.Lock()
+	c.stats.MessagesReceived++
+	c.stats.RawBytesReceived += uint64(len(data))
+	c.stats.WireBytesRecv += uint64(wire)
+	c.mu.Unlock()
+	return data, nil
+}
+
+// Stats returns a snapshot of the traffic counters.
+func (c *CompressedConnection) Stats() ConnStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,172 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompressedConnection_RoundTrip(t *testing.T) {
+	a, b := NewMemPair()
+	ca := NewCompressedConnection(a, 64)
+	cb := NewCompressedConnection(b, 64)
+	ctx := context.Background()
+
+	small := []byte(`{"jsonrpc":"2.0","method":"ping"}`)
+	big := []byte(`{"jsonrpc":"2.0","method":"bulk","params":"` + strings.Repeat("order ", 500) + `"}`)
+
+	for _, msg := range [][]byte{small, big} {
+		if err := ca.Send(ctx, msg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Below the threshold the message goes out as is.
+	if wire := <-a.Out; !bytes.Equal(wire, small) {
+		t.Fatalf("small message changed on the wire: %q", wire)
+	}
+	wire := <-a.Out
+	if !bytes.HasPrefix(wire, gzipMagic) || len(wire) >= len(big) {
+		t.Fatalf("big message not compressed: %d bytes", len(wire))
+	}
+
+	b.In <- small
+	b.In <- wire
+	for _, want := range [][]byte{small, big} {
+		got, err := cb.Receive(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Fatalf("got %d bytes, want %d", len(got), len(want))
+		}
+	}
+
+	st := ca.Stats()
+	if st.MessagesSent != 2 || st.RawBytesSent != uint64(len(small)+len(big)) {
+		t.Fatalf("unexpected send stats: %+v", st)
+	}
+	if r := st.Ratio(); r >= 0.5 {
+		t.Fatalf("ratio = %.2f, want a real gain", r)
+	}
+	if rs := cb.Stats(); rs.WireBytesRecv != st.WireBytesSent {
+		t.Fatalf("receiver saw %d wire bytes, sender %d", rs.WireBytesRecv, st.WireBytesSent)
+	}
+}
+
+func TestCompressedConnection_ReadLimit(t *testing.T) {
+	a, b := NewMemPair()
+	ca := NewCompressedConnection(a, 64)
+	cb := NewCompressedConnection(b, 64)
+	cb.MaxSize = 4096
+	ctx := context.Background()
+
+	// 1 MiB of zeros shrinks to about 1 KiB on the wire.
+	bomb := make([]byte, 1<<20)
+	if err := ca.Send(ctx, bomb); err != nil {
+		t.Fatal(err)
+	}
+	wire := <-a.Out
+	if len(wire) > 4096 {
+		t.Fatalf("expected a small frame, got %d bytes", len(wire))
+	}
+	b.In <- wire
+	if _, err := cb.Receive(ctx); !errors.Is(err, ErrMessageTooLarge) {
+		t.Fatalf("expected ErrMessageTooLarge, got %v", err)
+	}
+
+	// Exactly at the limit is fine.
+	ca.Send(ctx, make([]byte, 4096))
+	b.In <- <-a.Out
+	if got, err := cb.Receive(ctx); err != nil || len(got) != 4096 {
+		t.Fatalf("got %d bytes (%v)", len(got), err)
+	}
+}
+
+func TestWSProvider_NegotiateCompression(t *testing.T) {
+	cases := []struct {
+		name           string
+		server, client Compression
+		wantGzip       bool
+	}{
+		{"both", CompressionGzip, CompressionGzip, true},
+		{"old server", CompressionNone, CompressionGzip, false},
+		{"old client", CompressionGzip, CompressionNone, false},
+		{"deflate", CompressionDeflate, CompressionDeflate, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			addr := freeAddr(t)
+			server := NewWSProvider(nil)
+			server.Compression = tc.server
+			found := make(chan Connection, 1)
+			go server.Listen(ctx, addr, found)
+
+			client := NewWSProvider(nil)
+			client.Compression = tc.client
+			var conn Connection
+			var err error
+			for i := 0; i < 50; i++ {
+				if conn, err = client.Dial(ctx, "ws://"+addr+"/ws"); err == nil {
+					break
+				}
+				time.Sleep(20 * time.Millisecond)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			peer := <-found
+
+			_, clientGzip := conn.(*CompressedConnection)
+			_, serverGzip := peer.(*CompressedConnection)
+			if clientGzip != tc.wantGzip || serverGzip != tc.wantGzip {
+				t.Fatalf("gzip client=%v server=%v, want %v", clientGzip, serverGzip, tc.wantGzip)
+			}
+
+			msg := []byte(`{"params":"` + strings.Repeat("x", 4096) + `"}`)
+			if err := conn.Send(ctx, msg); err != nil {
+				t.Fatal(err)
+			}
+			got, err := peer.Receive(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, msg) {
+				t.Fatalf("payload mismatch")
+			}
+
+			// permessage-deflate reports its ratio on the WSConnection.
+			if tc.server == CompressionDeflate {
+				sent := conn.(StatsReporter).Stats()
+				recv := peer.(StatsReporter).Stats()
+				if sent.RawBytesSent != uint64(len(msg)) || sent.WireBytesSent >= sent.RawBytesSent {
+					t.Fatalf("unexpected send stats: %+v", sent)
+				}
+				if recv.RawBytesReceived != uint64(len(msg)) || recv.WireBytesRecv >= recv.RawBytesReceived {
+					t.Fatalf("unexpected receive stats: %+v", recv)
+				}
+			}
+		})
+	}
+}
+
+func freeAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().String()
+}
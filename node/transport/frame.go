@@ -0,0 +1,22 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+// IsBinaryFrame reports whether data is a binary frame rather than JSON
+// text. JSON text starts with '{', '[' or whitespace; binary frames
+// (e.g. attachment chunks) start with a control byte as type marker.
+//
+// Reserved markers: 0x01 rpc attachment chunk, 0x02 secure envelope,
+// 0x1f gzip (CompressedConnection).
+func IsBinaryFrame(data []byte) bool {
+	if len(data) == 0 {
+		return false
+	}
+	switch b := data[0]; b {
+	case '\t', '\n', '\r':
+		return false
+	default:
+		return b < 0x20
+	}
+}
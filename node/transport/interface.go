@@ -11,11 +11,45 @@ type Connection interface {
 	Close(reason string) error
 }
 
-type WSService interface {
-	Listen(addr string, found chan<- Connection) error
-	Dial(ctx context.Context, url string) (Connection, error)
+// Dialer establishes client connections. rpc.Node and the cluster
+// package use it to (re)connect, so any implementation - a proxying
+// dialer, a test double - plugs into the reconnect logic.
+type Dialer interface {
+	Dial(ctx context.Context, addr string) (Connection, error)
 }
 
+// Listener accepts server connections on addr and hands them to found
+// until ctx ends.
+type Listener interface {
+	Listen(ctx context.Context, addr string, found chan<- Connection) error
+}
+
+// Provider is a transport that can both listen and dial.
+// WSProvider and MemProvider implement it.
+type Provider interface {
+	Dialer
+	Listener
+}
+
+// Deprecated: WSService is Provider.
+type WSService = Provider
+
+// DialerFunc adapts a function to the Dialer interface.
+type DialerFunc func(ctx context.Context, addr string) (Connection, error)
+
+func (f DialerFunc) Dial(ctx context.Context, addr string) (Connection, error) {
+	return f(ctx, addr)
+}
+
+var (
+	_ Provider = (*WSProvider)(nil)
+	_ Provider = (*MemProvider)(nil)
+)
+
+// ConnWrapper decorates a freshly established connection. It may run a
+// handshake on conn and fails if the peer is not acceptable.
+type ConnWrapper func(ctx context.Context, conn Connection) (Connection, error)
+
 type LogSink interface {
 	Debug(msg string)
 	Info(msg string)
@@ -0,0 +1,196 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// MemNetwork is an in-memory network for tests. Hosts get a MemProvider
+// each; providers listen on and dial named addresses. The network can
+// add latency, partition hosts and cut connections.
+//
+//	net := transport.NewMemNetwork()
+//	server := net.Provider("payment")
+//	go server.Listen(ctx, "payment:8080", found)
+//
+//	client := net.Provider("order")
+//	node := rpc.NewNode(conn, client, "payment:8080", logger) // reconnects via client
+//
+//	net.Partition("order", "payment") // cut and refuse dials until Heal
+type MemNetwork struct {
+	mu         sync.Mutex
+	listeners  map[string]*memListener // by address
+	links      map[*memLink][2]string  // open connections and their hosts
+	partitions map[[2]string]bool
+
+	latency time.Duration
+	jitter  time.Duration
+	rnd     *rand.Rand
+}
+
+type memListener struct {
+	host  string
+	found chan<- Connection
+	ctx   context.Context
+}
+
+func NewMemNetwork() *MemNetwork {
+	return &MemNetwork{
+		listeners:  make(map[string]*memListener),
+		links:      make(map[*memLink][2]string),
+		partitions: make(map[[2]string]bool),
+		rnd:        rand.New(rand.NewSource(1)),
+	}
+}
+
+// Provider returns the provider of host. The host name is used for
+// partitions and Disconnect, not for addressing.
+func (n *MemNetwork) Provider(host string) *MemProvider {
+	return &MemProvider{network: n, host: host, Log: &SilentLogger{}}
+}
+
+// SetLatency delays every frame by d plus a random share of jitter.
+func (n *MemNetwork) SetLatency(d, jitter time.Duration) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.latency, n.jitter = d, jitter
+}
+
+// Partition cuts all connections between hosts a and b and refuses
+// new dials between them until Heal.
+func (n *MemNetwork) Partition(a, b string) {
+	n.mu.Lock()
+	n.partitions[hostPair(a, b)] = true
+	var cut []*memLink
+	for l, hosts := range n.links {
+		if hostPair(hosts[0], hosts[1]) == hostPair(a, b) {
+			cut = append(cut, l)
+		}
+	}
+	n.mu.Unlock()
+
+	for _, l := range cut {
+		l.close()
+	}
+}
+
+// Heal ends a partition.
+func (n *MemNetwork) Heal(a, b string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	delete(n.partitions, hostPair(a, b))
+}
+
+// Disconnect closes all connections of host (or all, if host is "").
+// Dialing keeps working, so reconnects succeed.
+func (n *MemNetwork) Disconnect(host string) int {
+	n.mu.Lock()
+	var cut []*memLink
+	for l, hosts := range n.links {
+		if host == "" || hosts[0] == host || hosts[1] == host {
+			cut = append(cut, l)
+		}
+	}
+	n.mu.Unlock()
+
+	for _, l := range cut {
+		l.close()
+	}
+	return len(cut)
+}
+
+// Connections returns the number of open connections.
+func (n *MemNetwork) Connections() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.links)
+}
+
+func (n *MemNetwork) delay() time.Duration {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	d := n.latency
+	if n.jitter > 0 {
+		d += time.Duration(n.rnd.Int63n(int64(n.jitter)))
+	}
+	return d
+}
+
+func hostPair(a, b string) [2]string {
+	if a > b {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// MemProvider is the MemNetwork counterpart of WSProvider.
+type MemProvider struct {
+	network *MemNetwork
+	host    string
+
+	Log LogSink
+}
+
+// Listen accepts connections on addr until ctx ends, like WSProvider.Listen.
+func (p *MemProvider) Listen(ctx context.Context, addr string, found chan<- Connection) error {
+	n := p.network
+	n.mu.Lock()
+	if _, taken := n.listeners[addr]; taken {
+		n.mu.Unlock()
+		return fmt.Errorf("mem listen %s: address in use", addr)
+	}
+	n.listeners[addr] = &memListener{host: p.host, found: found, ctx: ctx}
+	n.mu.Unlock()
+	p.Log.With("addr", addr).Info("Mem listener started")
+
+	<-ctx.Done()
+
+	n.mu.Lock()
+	delete(n.listeners, addr)
+	n.mu.Unlock()
+	return nil
+}
+
+// Dial connects to the listener on addr.
+func (p *MemProvider) Dial(ctx context.Context, addr string) (Connection, error) {
+	n := p.network
+	n.mu.Lock()
+	l, ok := n.listeners[addr]
+	if !ok || l.ctx.Err() != nil {
+		n.mu.Unlock()
+		return nil, fmt.Errorf("mem dial %s: connection refused", addr)
+	}
+	if n.partitions[hostPair(p.host, l.host)] {
+		n.mu.Unlock()
+		return nil, fmt.Errorf("mem dial %s: network unreachable", addr)
+	}
+
+	link := newMemLink()
+	link.latency = n.delay
+	link.onClose = func() {
+		n.mu.Lock()
+		delete(n.links, link)
+		n.mu.Unlock()
+	}
+	n.links[link] = [2]string{p.host, l.host}
+	n.mu.Unlock()
+
+	client, server := newMemPair(link)
+	select {
+	case l.found <- server:
+		return client, nil
+	case <-l.ctx.Done():
+	case <-ctx.Done():
+	}
+	link.close()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	return nil, fmt.Errorf("mem dial %s: connection refused", addr)
+}
@@ -0,0 +1,78 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemNetwork(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	net := NewMemNetwork()
+	found := make(chan Connection, 1)
+	lctx, stopListen := context.WithCancel(ctx)
+	go net.Provider("server").Listen(lctx, "svc", found)
+	client := net.Provider("client")
+
+	var conn Connection
+	var err error
+	for i := 0; i < 100; i++ {
+		if conn, err = client.Dial(ctx, "svc"); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	peer := <-found
+
+	if _, err := client.Dial(ctx, "other"); err == nil {
+		t.Fatal("dial to unknown address must fail")
+	}
+
+	net.SetLatency(20*time.Millisecond, 0)
+	start := time.Now()
+	if err := conn.Send(ctx, []byte("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := peer.Receive(ctx); err != nil || string(data) != "hi" {
+		t.Fatalf("receive: %q %v", data, err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Fatal("latency not applied")
+	}
+	net.SetLatency(0, 0)
+
+	// Closing one side ends the other.
+	conn.Close("bye")
+	if _, err := peer.Receive(ctx); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+	if err := conn.Close("again"); err != nil {
+		t.Fatal(err)
+	}
+	if net.Connections() != 0 {
+		t.Fatalf("closed connection still tracked")
+	}
+
+	stopListen()
+	time.Sleep(5 * time.Millisecond)
+	if _, err := client.Dial(ctx, "svc"); err == nil {
+		t.Fatal("dial after listener stopped must fail")
+	}
+}
+
+func TestMemConnection_LiteralClose(t *testing.T) {
+	m := &MemConnection{In: make(chan []byte), Out: make(chan []byte)}
+	m.Close("done")
+	if err := m.Send(context.Background(), []byte("x")); !errors.Is(err, ErrClosed) {
+		t.Fatalf("expected ErrClosed, got %v", err)
+	}
+}
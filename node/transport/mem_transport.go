@@ -6,46 +6,117 @@ package transport
 import (
 	"context"
 	"errors"
+	"sync"
+	"time"
 )
 
+// ErrClosed is returned by a MemConnection after either side closed it.
+var ErrClosed = errors.New("connection closed")
+
 // MemConnection implementiert transport.Connection für In-Memory Tests
 type MemConnection struct {
 	In  chan []byte
 	Out chan []byte
+
+	// Both ends of a pair share one link; connections built as literals
+	// get their own on first use.
+	linkOnce sync.Once
+	link     *memLink
+}
+
+// memLink is the state shared by both ends of a connection.
+type memLink struct {
+	done      chan struct{}
+	closeOnce sync.Once
+	latency   func() time.Duration // nil: none
+	onClose   func()
+}
+
+func newMemLink() *memLink {
+	return &memLink{done: make(chan struct{})}
+}
+
+func (l *memLink) close() {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		if l.onClose != nil {
+			l.onClose()
+		}
+	})
+}
+
+func (m *MemConnection) getLink() *memLink {
+	m.linkOnce.Do(func() {
+		if m.link == nil {
+			m.link = newMemLink()
+		}
+	})
+	return m.link
 }
 
 func (m *MemConnection) Send(ctx context.Context, data []byte) error {
+	link := m.getLink()
+	if link.latency != nil {
+		if d := link.latency(); d > 0 {
+			t := time.NewTimer(d)
+			select {
+			case <-t.C:
+			case <-ctx.Done():
+				t.Stop()
+				return ctx.Err()
+			case <-link.done:
+				t.Stop()
+				return ErrClosed
+			}
+		}
+	}
+
+	select {
+	case <-link.done:
+		return ErrClosed
+	default:
+	}
 	select {
 	case m.Out <- data:
 		return nil
 	case <-ctx.Done():
 		return ctx.Err()
+	case <-link.done:
+		return ErrClosed
 	}
 }
 
 func (m *MemConnection) Receive(ctx context.Context) ([]byte, error) {
+	link := m.getLink()
 	select {
 	case data, ok := <-m.In:
 		if !ok {
-			return nil, errors.New("connection closed")
+			return nil, ErrClosed
 		}
 		return data, nil
 	case <-ctx.Done():
 		return nil, ctx.Err()
+	case <-link.done:
+		return nil, ErrClosed
 	}
 }
 
+// Close ends the connection for both sides; pending and later
+// Send/Receive calls fail with ErrClosed. It is safe to call repeatedly.
 func (m *MemConnection) Close(reason string) error {
-	// In a real test, one would be cautious here,
-	// to avoid "close of closed channel".
+	m.getLink().close()
 	return nil
 }
 
 // NewMemPair creates two fully connected endpoints.
 func NewMemPair() (*MemConnection, *MemConnection) {
+	return newMemPair(newMemLink())
+}
+
+func newMemPair(link *memLink) (*MemConnection, *MemConnection) {
 	aToB := make(chan []byte, 10)
 	bToA := make(chan []byte, 10)
 
-	return &MemConnection{In: bToA, Out: aToB},
-		&MemConnection{In: aToB, Out: bToA}
+	return &MemConnection{In: bToA, Out: aToB, link: link},
+		&MemConnection{In: aToB, Out: bToA, link: link}
 }
@@ -0,0 +1,40 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+// Metrics is the reporting interface for Node, WSProvider and WSConnection.
+// Labels are passed as key/value pairs: "method", "ping", "code", "ok".
+// Like LogSink it keeps the core free of any metrics library;
+// node/metrics contains a small in-memory implementation.
+type Metrics interface {
+	Counter(name string, delta float64, labels ...string)
+	Gauge(name string, value float64, labels ...string)
+	Observe(name string, value float64, labels ...string)
+}
+
+// Metric names reported by the transport layer.
+const (
+	MetricWSAccepted      = "nexio_ws_connections_accepted_total"
+	MetricWSDials         = "nexio_ws_dials_total"
+	MetricWSBytesSent     = "nexio_ws_bytes_sent_total"
+	MetricWSBytesReceived = "nexio_ws_bytes_received_total"
+	MetricWSMessagesSent  = "nexio_ws_messages_sent_total"
+	MetricWSMessagesRecv  = "nexio_ws_messages_received_total"
+)
+
+// default in Constructor: NoopMetrics
+// => no nil checks if metrics are not explicitly set
+type NoopMetrics struct{}
+
+func (NoopMetrics) Counter(name string, delta float64, labels ...string) {}
+func (NoopMetrics) Gauge(name string, value float64, labels ...string)   {}
+func (NoopMetrics) Observe(name string, value float64, labels ...string) {}
+
+// MetricsOrNoop returns m, or NoopMetrics if m is nil.
+func MetricsOrNoop(m Metrics) Metrics {
+	if m == nil {
+		return NoopMetrics{}
+	}
+	return m
+}
@@ -0,0 +1,57 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+	"sync"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Counting counts messages and bytes in both directions.
+// It implements transport.StatsReporter; raw and wire sizes are the
+// same at this layer.
+type Counting struct {
+	transport.Connection
+
+	mu    sync.Mutex
+	stats transport.ConnStats
+}
+
+func NewCounting(conn transport.Connection) *Counting {
+	return &Counting{Connection: conn}
+}
+
+func (c *Counting) Send(ctx context.Context, data []byte) error {
+	if err := c.Connection.Send(ctx, data); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.stats.MessagesSent++
+	c.stats.RawBytesSent += uint64(len(data))
+	c.stats.WireBytesSent += uint64(len(data))
+	c.mu.Unlock()
+	return nil
+}
+
+func (c *Counting) Receive(ctx context.Context) ([]byte, error) {
+	data, err := c.Connection.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	c.stats.MessagesReceived++
+	c.stats.RawBytesReceived += uint64(len(data))
+	c.stats.WireBytesRecv += uint64(len(data))
+	c.mu.Unlock()
+	return data, nil
+}
+
+// Stats returns a snapshot of the counters.
+func (c *Counting) Stats() transport.ConnStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
@@ -0,0 +1,19 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package middleware contains decorators for transport.Connection. Each one
+wraps any Connection (WSConnection, MemConnection, a secure.Conn or another
+decorator) and is a Connection itself, so they stack freely:
+
+	var conn transport.Connection = raw
+	conn = middleware.NewCounting(conn)
+	conn = middleware.NewLogging(conn, logger, 256)
+	conn = middleware.NewRateLimit(conn, 100, 20) // 100 msgs/s, burst 20
+
+	node := rpc.NewNode(conn, nil, "", logger)
+
+For chaos tests Faults drops, delays, duplicates or corrupts outgoing
+frames; Record writes all traffic as JSON lines to an io.Writer.
+*/
+package middleware
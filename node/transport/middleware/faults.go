@@ -0,0 +1,84 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// FaultConfig sets the probabilities (0..1) of faults per outgoing frame.
+type FaultConfig struct {
+	Drop      float64
+	Duplicate float64
+	Corrupt   float64 // flips one random byte
+
+	Delay  time.Duration // fixed delay before each frame
+	Jitter time.Duration // additional random delay up to Jitter
+
+	// Seed makes the fault sequence reproducible (0 = time based).
+	Seed int64
+}
+
+// Faults injects network faults into outgoing frames for chaos tests.
+// A dropped frame still reports success to the caller, like a lossy link.
+type Faults struct {
+	transport.Connection
+	cfg FaultConfig
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+func NewFaults(conn transport.Connection, cfg FaultConfig) *Faults {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Faults{Connection: conn, cfg: cfg, rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (f *Faults) Send(ctx context.Context, data []byte) error {
+	f.mu.Lock()
+	delay := f.cfg.Delay
+	if f.cfg.Jitter > 0 {
+		delay += time.Duration(f.rnd.Int63n(int64(f.cfg.Jitter)))
+	}
+	drop := f.hit(f.cfg.Drop)
+	dup := f.hit(f.cfg.Duplicate)
+	if f.hit(f.cfg.Corrupt) && len(data) > 0 {
+		data = bytes.Clone(data)
+		data[f.rnd.Intn(len(data))] ^= 0xff
+	}
+	f.mu.Unlock()
+
+	if delay > 0 {
+		t := time.NewTimer(delay)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+	if drop {
+		return nil
+	}
+	if err := f.Connection.Send(ctx, data); err != nil {
+		return err
+	}
+	if dup {
+		return f.Connection.Send(ctx, data)
+	}
+	return nil
+}
+
+func (f *Faults) hit(p float64) bool {
+	return p > 0 && f.rnd.Float64() < p
+}
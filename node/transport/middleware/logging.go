@@ -0,0 +1,85 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Direction of a frame as seen from the wrapped side.
+const (
+	DirOut = "out"
+	DirIn  = "in"
+)
+
+// Logging logs every frame at debug level with its direction and size.
+// Up to MaxDump bytes of the frame are included (0 = size only).
+type Logging struct {
+	transport.Connection
+	MaxDump int
+
+	Log transport.LogSink
+}
+
+func NewLogging(conn transport.Connection, logger transport.LogSink, maxDump int) *Logging {
+	l := &Logging{Connection: conn, MaxDump: maxDump, Log: &transport.SilentLogger{}}
+	if logger != nil {
+		l.Log = logger
+	}
+	return l
+}
+
+func (l *Logging) Send(ctx context.Context, data []byte) error {
+	err := l.Connection.Send(ctx, data)
+	l.log(DirOut, data, err)
+	return err
+}
+
+func (l *Logging) Receive(ctx context.Context) ([]byte, error) {
+	data, err := l.Connection.Receive(ctx)
+	l.log(DirIn, data, err)
+	return data, err
+}
+
+func (l *Logging) Close(reason string) error {
+	l.Log.With("reason", reason).Debug("Connection closed")
+	return l.Connection.Close(reason)
+}
+
+func (l *Logging) log(dir string, data []byte, err error) {
+	if err != nil {
+		l.Log.With("dir", dir).With("error", err).Debug("Frame failed")
+		return
+	}
+	log := l.Log.With("dir", dir).With("bytes", len(data))
+	if l.MaxDump > 0 {
+		log = log.With("frame", dump(data, l.MaxDump))
+	}
+	log.Debug("Frame")
+}
+
+// dump renders JSON frames as text and binary frames as hex.
+func dump(data []byte, max int) string {
+	cut := data
+	if len(cut) > max {
+		cut = cut[:max]
+	}
+	var s string
+	if transport.IsBinaryFrame(data) {
+		const hexdigits = "0123456789abcdef"
+		b := make([]byte, 0, 2*len(cut))
+		for _, c := range cut {
+			b = append(b, hexdigits[c>>4], hexdigits[c&0x0f])
+		}
+		s = string(b)
+	} else {
+		s = string(cut)
+	}
+	if len(cut) < len(data) {
+		s += "..."
+	}
+	return s
+}
@@ -0,0 +1,143 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// sink collects log lines with their fields.
+type sink struct {
+	mu  *sync.Mutex
+	out *[]string
+	kv  string
+}
+
+func newSink() *sink { return &sink{mu: &sync.Mutex{}, out: &[]string{}} }
+
+func (s *sink) add(level, msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	*s.out = append(*s.out, level+" "+msg+s.kv)
+}
+func (s *sink) Debug(msg string) { s.add("DEBUG", msg) }
+func (s *sink) Info(msg string)  { s.add("INFO", msg) }
+func (s *sink) Warn(msg string)  { s.add("WARN", msg) }
+func (s *sink) Error(msg string) { s.add("ERROR", msg) }
+func (s *sink) With(key string, value any) transport.LogSink {
+	return &sink{mu: s.mu, out: s.out, kv: fmt.Sprintf("%s %s=%v", s.kv, key, value)}
+}
+
+func TestStack_CountLogRecord(t *testing.T) {
+	ctx := context.Background()
+	a, b := transport.NewMemPair()
+
+	var rec bytes.Buffer
+	log := newSink()
+	counted := NewCounting(a)
+	var conn transport.Connection = NewRecord(NewLogging(counted, log, 8), &rec)
+
+	if err := conn.Send(ctx, []byte(`{"method":"ping"}`)); err != nil {
+		t.Fatal(err)
+	}
+	b.Out <- []byte{0x01, 0xab}
+	if _, err := conn.Receive(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	st := counted.Stats()
+	if st.MessagesSent != 1 || st.MessagesReceived != 1 || st.RawBytesSent != 17 || st.RawBytesReceived != 2 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	want := []string{
+		`DEBUG Frame dir=out bytes=17 frame={"method...`,
+		`DEBUG Frame dir=in bytes=2 frame=01ab`,
+	}
+	if strings.Join(*log.out, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("log:\n%s", strings.Join(*log.out, "\n"))
+	}
+
+	var frames []Frame
+	sc := bufio.NewScanner(&rec)
+	for sc.Scan() {
+		var f Frame
+		if err := json.Unmarshal(sc.Bytes(), &f); err != nil {
+			t.Fatal(err)
+		}
+		frames = append(frames, f)
+	}
+	if len(frames) != 2 || frames[0].Dir != DirOut || frames[0].Text != `{"method":"ping"}` ||
+		frames[1].Dir != DirIn || !bytes.Equal(frames[1].Data(), []byte{0x01, 0xab}) {
+		t.Fatalf("unexpected recording %+v", frames)
+	}
+}
+
+func TestFaults(t *testing.T) {
+	ctx := context.Background()
+	msg := []byte(`{"id":"1"}`)
+
+	a, _ := transport.NewMemPair()
+	if err := NewFaults(a, FaultConfig{Drop: 1}).Send(ctx, msg); err != nil || len(a.Out) != 0 {
+		t.Fatalf("drop: err=%v queued=%d", err, len(a.Out))
+	}
+
+	a, _ = transport.NewMemPair()
+	_ = NewFaults(a, FaultConfig{Duplicate: 1}).Send(ctx, msg)
+	if len(a.Out) != 2 {
+		t.Fatalf("duplicate: %d frames", len(a.Out))
+	}
+
+	a, _ = transport.NewMemPair()
+	_ = NewFaults(a, FaultConfig{Corrupt: 1, Seed: 7}).Send(ctx, msg)
+	if got := <-a.Out; bytes.Equal(got, msg) || len(got) != len(msg) {
+		t.Fatalf("corrupt: %q", got)
+	}
+	if string(msg) != `{"id":"1"}` {
+		t.Fatal("corrupt must not modify the caller's buffer")
+	}
+
+	a, _ = transport.NewMemPair()
+	start := time.Now()
+	_ = NewFaults(a, FaultConfig{Delay: 30 * time.Millisecond}).Send(ctx, msg)
+	if time.Since(start) < 30*time.Millisecond {
+		t.Fatal("delay not applied")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	a, _ := transport.NewMemPair()
+	rl := NewRateLimit(a, 50, 2)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := rl.Send(ctx, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Burst of 2, then 2 more at 50/s: at least ~40ms.
+	if d := time.Since(start); d < 35*time.Millisecond {
+		t.Fatalf("4 sends took %v, limit not applied", d)
+	}
+
+	// Without refill a blocked Send ends with its ctx.
+	empty := NewRateLimit(a, 0, 1)
+	_ = empty.Send(ctx, []byte("{}"))
+	cctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if err := empty.Send(cctx, []byte("{}")); err != context.DeadlineExceeded {
+		t.Fatalf("expected deadline, got %v", err)
+	}
+}
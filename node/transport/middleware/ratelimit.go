@@ -0,0 +1,77 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// RateLimit throttles outgoing frames with a token bucket. Send blocks
+// until a token is free or ctx ends; incoming frames are not limited.
+type RateLimit struct {
+	transport.Connection
+
+	mu     sync.Mutex
+	rate   float64 // tokens per second
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimit allows perSecond frames on average and bursts of up to
+// burst frames (at least 1).
+func NewRateLimit(conn transport.Connection, perSecond float64, burst int) *RateLimit {
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimit{
+		Connection: conn,
+		rate:       perSecond,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		last:       time.Now(),
+	}
+}
+
+func (r *RateLimit) Send(ctx context.Context, data []byte) error {
+	for {
+		wait := r.take()
+		if wait == 0 {
+			return r.Connection.Send(ctx, data)
+		}
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// take consumes a token, or returns how long to wait for the next one.
+func (r *RateLimit) take() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	r.last = now
+
+	if r.tokens >= 1 {
+		r.tokens--
+		return 0
+	}
+	if r.rate <= 0 {
+		return time.Hour
+	}
+	return time.Duration((1 - r.tokens) / r.rate * float64(time.Second))
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// Frame is one line of a recording. JSON frames are kept as text for
+// readability, binary frames as base64 in Bin.
+type Frame struct {
+	T    time.Time `json:"t"`
+	Dir  string    `json:"dir"`
+	Text string    `json:"text,omitempty"`
+	Bin  []byte    `json:"bin,omitempty"`
+}
+
+// Data returns the frame payload.
+func (f Frame) Data() []byte {
+	if f.Bin != nil {
+		return f.Bin
+	}
+	return []byte(f.Text)
+}
+
+// Record writes every frame that passes the connection to W as a JSON line.
+// Write errors are reported once to Log and do not affect the connection.
+type Record struct {
+	transport.Connection
+
+	mu     sync.Mutex
+	enc    *json.Encoder
+	failed bool
+
+	Log transport.LogSink
+}
+
+func NewRecord(conn transport.Connection, w io.Writer) *Record {
+	return &Record{Connection: conn, enc: json.NewEncoder(w), Log: &transport.SilentLogger{}}
+}
+
+func (r *Record) Send(ctx context.Context, data []byte) error {
+	if err := r.Connection.Send(ctx, data); err != nil {
+		return err
+	}
+	r.write(DirOut, data)
+	return nil
+}
+
+func (r *Record) Receive(ctx context.Context) ([]byte, error) {
+	data, err := r.Connection.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r.write(DirIn, data)
+	return data, nil
+}
+
+func (r *Record) write(dir string, data []byte) {
+	f := Frame{T: time.Now(), Dir: dir}
+	if transport.IsBinaryFrame(data) {
+		f.Bin = data
+	} else {
+		f.Text = string(data)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.enc.Encode(f); err != nil && !r.failed {
+		r.failed = true
+		r.Log.With("error", err).Error("Recording failed")
+	}
+}
@@ -0,0 +1,29 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+/*
+Package secure adds an end-to-end security envelope to a
+transport.Connection, independent of TLS and of any relay in between.
+
+Both peers exchange a hello with their Ed25519 identity and an ephemeral
+X25519 key, signed by the identity. Every frame afterwards carries a
+sequence number and an Ed25519 signature; with Encrypt set the payload
+is also sealed with AES-256-GCM under per-direction keys derived from the
+X25519 secret. Replayed, reordered or forged frames are dropped.
+
+	id, _ := secure.GenerateKey()
+	cfg := &secure.Config{
+		Key:        id,
+		Encrypt:    true,
+		VerifyPeer: secure.AllowKeys(paymentKey),
+	}
+
+	conn, err := cfg.Handshake(ctx, rawConn)
+	node := rpc.NewNode(conn, nil, "", logger)
+
+For reconnecting Nodes set the provider's Wrap, then every dialed and
+accepted connection runs the handshake:
+
+	provider.Wrap = cfg.Wrap
+*/
+package secure
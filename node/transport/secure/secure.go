@@ -0,0 +1,312 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package secure
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/georghagn/nexio/node/transport"
+)
+
+var (
+	ErrHandshake = errors.New("secure: handshake failed")
+	ErrPeer      = errors.New("secure: peer not accepted")
+)
+
+// Envelope layout:
+//
+//	hello: marker | kindHello | flags | ed25519 pub (32) | x25519 pub (32) | sig (64)
+//	data:  marker | kindData  | seq (uint64 BE) | body | sig (64)
+//
+// The data signature covers session id, header and body, so frames of
+// one session cannot be replayed into another.
+const (
+	marker    byte = 0x02
+	kindHello byte = 0
+	kindData  byte = 1
+
+	flagEncrypt byte = 1
+
+	helloLen  = 3 + ed25519.PublicKeySize + 32 + ed25519.SignatureSize
+	dataHdr   = 10
+	helloCtx  = "nexio secure hello v1"
+	keyLength = 32
+)
+
+// GenerateKey creates a new Ed25519 identity.
+func GenerateKey() (ed25519.PrivateKey, error) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	return priv, err
+}
+
+// AllowKeys returns a VerifyPeer func accepting only the given identities.
+func AllowKeys(keys ...ed25519.PublicKey) func(ed25519.PublicKey) error {
+	return func(peer ed25519.PublicKey) error {
+		for _, k := range keys {
+			if k.Equal(peer) {
+				return nil
+			}
+		}
+		return ErrPeer
+	}
+}
+
+// Config holds the local identity and the policy for peers.
+type Config struct {
+	Key ed25519.PrivateKey
+
+	// Encrypt seals payloads in addition to signing them. The session is
+	// encrypted if either side asks for it.
+	Encrypt bool
+
+	// VerifyPeer decides whether the peer identity is acceptable.
+	// nil accepts any identity (frames are still authenticated per session).
+	VerifyPeer func(peer ed25519.PublicKey) error
+
+	Log transport.LogSink
+}
+
+// Wrap is a transport.ConnWrapper running the handshake.
+func (c *Config) Wrap(ctx context.Context, conn transport.Connection) (transport.Connection, error) {
+	return c.Handshake(ctx, conn)
+}
+
+// Handshake exchanges hellos on conn and returns the secured connection.
+// Both sides call it; there are no client or server roles.
+func (c *Config) Handshake(ctx context.Context, conn transport.Connection) (*Conn, error) {
+	if len(c.Key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w: no identity key", ErrHandshake)
+	}
+	eph, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var flags byte
+	if c.Encrypt {
+		flags |= flagEncrypt
+	}
+	own := c.Key.Public().(ed25519.PublicKey)
+	hello := make([]byte, 0, helloLen)
+	hello = append(hello, marker, kindHello, flags)
+	hello = append(hello, own...)
+	hello = append(hello, eph.PublicKey().Bytes()...)
+	hello = append(hello, ed25519.Sign(c.Key, helloSigned(hello))...)
+
+	if err := conn.Send(ctx, hello); err != nil {
+		return nil, err
+	}
+	peerHello, err := conn.Receive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(peerHello) != helloLen || peerHello[0] != marker || peerHello[1] != kindHello {
+		return nil, fmt.Errorf("%w: unexpected hello", ErrHandshake)
+	}
+
+	peerKey := ed25519.PublicKey(bytes.Clone(peerHello[3 : 3+ed25519.PublicKeySize]))
+	peerEph := peerHello[3+ed25519.PublicKeySize : helloLen-ed25519.SignatureSize]
+	if !ed25519.Verify(peerKey, helloSigned(peerHello[:helloLen-ed25519.SignatureSize]), peerHello[helloLen-ed25519.SignatureSize:]) {
+		return nil, fmt.Errorf("%w: bad hello signature", ErrHandshake)
+	}
+	// A relay echoing our own hello (and later our frames) must not pass
+	// for a peer, whatever VerifyPeer allows.
+	ownEph := eph.PublicKey().Bytes()
+	if bytes.Equal(peerEph, ownEph) || peerKey.Equal(own) {
+		return nil, fmt.Errorf("%w: reflected hello", ErrHandshake)
+	}
+	if c.VerifyPeer != nil {
+		if err := c.VerifyPeer(peerKey); err != nil {
+			return nil, err
+		}
+	}
+
+	peerPub, err := ecdh.X25519().NewPublicKey(peerEph)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHandshake, err)
+	}
+	shared, err := eph.ECDH(peerPub)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrHandshake, err)
+	}
+
+	// The side with the smaller ephemeral key is "a"; the session id
+	// binds both hellos, so every session has its own keys and ids.
+	first, second := ownEph, peerEph
+	if bytes.Compare(ownEph, peerEph) > 0 {
+		first, second = peerEph, ownEph
+	}
+	session := sha256.Sum256(append(append([]byte(helloCtx), first...), second...))
+
+	s := &Conn{
+		Connection: conn,
+		key:        c.Key,
+		peer:       peerKey,
+		session:    session[:],
+		Log:        &transport.SilentLogger{},
+	}
+	if c.Log != nil {
+		s.Log = c.Log
+	}
+
+	if (flags|peerHello[2])&flagEncrypt != 0 {
+		aKey := hkdf(shared, session[:], "nexio a->b")
+		bKey := hkdf(shared, session[:], "nexio b->a")
+		if bytes.Equal(first, ownEph) {
+			s.sendAEAD, err = newGCM(aKey)
+			if err == nil {
+				s.recvAEAD, err = newGCM(bKey)
+			}
+		} else {
+			s.sendAEAD, err = newGCM(bKey)
+			if err == nil {
+				s.recvAEAD, err = newGCM(aKey)
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func helloSigned(hello []byte) []byte {
+	return append([]byte(helloCtx), hello...)
+}
+
+// hkdf is HKDF-SHA256 (RFC 5869) for a single output block.
+func hkdf(secret, salt []byte, info string) []byte {
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	expand.Write([]byte(info))
+	expand.Write([]byte{1})
+	return expand.Sum(nil)[:keyLength]
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Conn is a secured transport.Connection.
+type Conn struct {
+	transport.Connection
+
+	key     ed25519.PrivateKey
+	peer    ed25519.PublicKey
+	session []byte
+
+	sendAEAD cipher.AEAD // nil: signed only
+	recvAEAD cipher.AEAD
+
+	sendMu  sync.Mutex
+	sendSeq uint64
+	recvSeq uint64 // only touched by Receive
+
+	Log transport.LogSink
+}
+
+// PeerKey returns the verified identity of the peer.
+func (s *Conn) PeerKey() ed25519.PublicKey {
+	return s.peer
+}
+
+// Encrypted reports whether payloads are sealed.
+func (s *Conn) Encrypted() bool {
+	return s.sendAEAD != nil
+}
+
+func (s *Conn) Send(ctx context.Context, data []byte) error {
+	// Sequence numbers have to hit the wire in order.
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	s.sendSeq++
+
+	hdr := make([]byte, dataHdr)
+	hdr[0], hdr[1] = marker, kindData
+	binary.BigEndian.PutUint64(hdr[2:], s.sendSeq)
+
+	body := data
+	if s.sendAEAD != nil {
+		body = s.sendAEAD.Seal(nil, nonce(s.sendSeq), data, hdr)
+	}
+
+	frame := make([]byte, 0, dataHdr+len(body)+ed25519.SignatureSize)
+	frame = append(frame, hdr...)
+	frame = append(frame, body...)
+	frame = append(frame, ed25519.Sign(s.key, s.signed(frame))...)
+	return s.Connection.Send(ctx, frame)
+}
+
+// Receive returns the next authentic frame. Forged, replayed or
+// undecryptable frames are logged and skipped.
+func (s *Conn) Receive(ctx context.Context) ([]byte, error) {
+	for {
+		frame, err := s.Connection.Receive(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := s.open(frame)
+		if err != nil {
+			s.Log.With("error", err).Warn("Secure frame dropped")
+			continue
+		}
+		return data, nil
+	}
+}
+
+func (s *Conn) open(frame []byte) ([]byte, error) {
+	if len(frame) < dataHdr+ed25519.SignatureSize || frame[0] != marker || frame[1] != kindData {
+		return nil, errors.New("not a secure data frame")
+	}
+	msg := frame[:len(frame)-ed25519.SignatureSize]
+	if !ed25519.Verify(s.peer, s.signed(msg), frame[len(msg):]) {
+		return nil, errors.New("bad signature")
+	}
+
+	seq := binary.BigEndian.Uint64(frame[2:dataHdr])
+	if seq <= s.recvSeq {
+		return nil, fmt.Errorf("replayed frame (seq %d, last %d)", seq, s.recvSeq)
+	}
+
+	body := msg[dataHdr:]
+	if s.recvAEAD != nil {
+		plain, err := s.recvAEAD.Open(nil, nonce(seq), body, frame[:dataHdr])
+		if err != nil {
+			return nil, err
+		}
+		body = plain
+	}
+	s.recvSeq = seq
+	return body, nil
+}
+
+func (s *Conn) signed(msg []byte) []byte {
+	return append(append(make([]byte, 0, len(s.session)+len(msg)), s.session...), msg...)
+}
+
+// nonce is unique per key: every direction has its own key and seq.
+func nonce(seq uint64) []byte {
+	n := make([]byte, 12)
+	binary.BigEndian.PutUint64(n[4:], seq)
+	return n
+}
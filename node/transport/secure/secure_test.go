@@ -0,0 +1,171 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package secure
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/georghagn/nexio/node/rpc"
+	"github.com/georghagn/nexio/node/transport"
+)
+
+// relay forwards frames between two pairs and keeps a copy of each.
+type relay struct {
+	mu   sync.Mutex
+	seen [][]byte
+}
+
+func (r *relay) forward(from, to chan []byte) {
+	for data := range from {
+		r.mu.Lock()
+		r.seen = append(r.seen, bytes.Clone(data))
+		r.mu.Unlock()
+		to <- data
+	}
+}
+
+func newRelayed() (client, server *transport.MemConnection, r *relay) {
+	c, rc := transport.NewMemPair()
+	rs, s := transport.NewMemPair()
+	r = &relay{}
+	go r.forward(rc.In, rs.Out)
+	go r.forward(rs.In, rc.Out)
+	return c, s, r
+}
+
+func handshake(t *testing.T, ctx context.Context, a, b transport.Connection, ca, cb *Config) (*Conn, *Conn) {
+	t.Helper()
+	var sb *Conn
+	var errB error
+	done := make(chan struct{})
+	go func() {
+		sb, errB = cb.Handshake(ctx, b)
+		close(done)
+	}()
+	sa, errA := ca.Handshake(ctx, a)
+	<-done
+	if errA != nil || errB != nil {
+		t.Fatalf("handshake: %v / %v", errA, errB)
+	}
+	return sa, sb
+}
+
+func TestSecureNodes_ThroughUntrustedRelay(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	clientKey, _ := GenerateKey()
+	serverKey, _ := GenerateKey()
+	rawClient, rawServer, r := newRelayed()
+
+	client, server := handshake(t, ctx, rawClient, rawServer,
+		&Config{Key: clientKey, Encrypt: true, VerifyPeer: AllowKeys(serverKey.Public().(ed25519.PublicKey))},
+		&Config{Key: serverKey, VerifyPeer: AllowKeys(clientKey.Public().(ed25519.PublicKey))},
+	)
+	if !client.Encrypted() || !server.Encrypted() {
+		t.Fatal("session should be encrypted if one side asks for it")
+	}
+	if !server.PeerKey().Equal(clientKey.Public()) {
+		t.Fatal("server sees the wrong peer identity")
+	}
+
+	serverNode := rpc.NewNode(server, nil, "", nil)
+	clientNode := rpc.NewNode(client, nil, "", nil)
+	serverNode.Register("secret.echo", func(ctx context.Context, p json.RawMessage) (any, error) {
+		return p, nil
+	})
+	go serverNode.Listen(ctx)
+	go clientNode.Listen(ctx)
+
+	res, err := clientNode.Call(ctx, "secret.echo", "top-secret-payload")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(res) != `"top-secret-payload"` {
+		t.Fatalf("unexpected result %s", res)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, f := range r.seen {
+		if bytes.Contains(f, []byte("top-secret")) || bytes.Contains(f, []byte("secret.echo")) {
+			t.Fatalf("relay saw plaintext: %q", f)
+		}
+	}
+}
+
+func TestSecureConn_DropsReplayedAndForgedFrames(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ka, _ := GenerateKey()
+	kb, _ := GenerateKey()
+	rawA, rawB := transport.NewMemPair()
+	a, b := handshake(t, ctx, rawA, rawB, &Config{Key: ka}, &Config{Key: kb})
+
+	if err := a.Send(ctx, []byte("one")); err != nil {
+		t.Fatal(err)
+	}
+	frame := <-rawA.Out
+
+	forged := bytes.Clone(frame)
+	forged[dataHdr] ^= 0xff // signed only, but tampering is detected
+
+	rawB.In <- frame
+	rawB.In <- frame  // replay
+	rawB.In <- forged // tampered copy
+	_ = a.Send(ctx, []byte("two"))
+	rawB.In <- <-rawA.Out
+
+	for _, want := range []string{"one", "two"} {
+		got, err := b.Receive(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != want {
+			t.Fatalf("got %q, want %q", got, want)
+		}
+	}
+}
+
+func TestHandshake_RejectsUnknownPeer(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	known, _ := GenerateKey()
+	stranger, _ := GenerateKey()
+	server, _ := GenerateKey()
+	rawA, rawB := transport.NewMemPair()
+
+	go (&Config{Key: stranger}).Handshake(ctx, rawA)
+	_, err := (&Config{Key: server, VerifyPeer: AllowKeys(known.Public().(ed25519.PublicKey))}).Handshake(ctx, rawB)
+	if !errors.Is(err, ErrPeer) {
+		t.Fatalf("expected ErrPeer, got %v", err)
+	}
+}
+
+func TestHandshake_RejectsReflectedHello(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	key, _ := GenerateKey()
+	conn, mirror := transport.NewMemPair()
+	go func() {
+		for data := range mirror.In {
+			mirror.Out <- data
+		}
+	}()
+
+	_, err := (&Config{Key: key}).Handshake(ctx, conn)
+	if !errors.Is(err, ErrHandshake) {
+		t.Fatalf("expected ErrHandshake for our own hello, got %v", err)
+	}
+}
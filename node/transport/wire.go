@@ -0,0 +1,80 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync/atomic"
+)
+
+// wireConn counts the bytes of the raw network connection below a
+// websocket, i.e. what permessage-deflate actually put on the wire
+// (including the handshake and websocket framing).
+type wireConn struct {
+	net.Conn
+	read, written atomic.Uint64
+}
+
+func (c *wireConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.read.Add(uint64(n))
+	return n, err
+}
+
+func (c *wireConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.written.Add(uint64(n))
+	return n, err
+}
+
+// wireListener hands out counted connections.
+type wireListener struct {
+	net.Listener
+}
+
+func (l wireListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &wireConn{Conn: c}, nil
+}
+
+type wireConnKey struct{}
+
+// withWireConn is an http.Server ConnContext that makes the counted
+// connection available to the upgrade handler.
+func withWireConn(ctx context.Context, c net.Conn) context.Context {
+	if wc, ok := c.(*wireConn); ok {
+		return context.WithValue(ctx, wireConnKey{}, wc)
+	}
+	return ctx
+}
+
+func wireConnFrom(r *http.Request) *wireConn {
+	wc, _ := r.Context().Value(wireConnKey{}).(*wireConn)
+	return wc
+}
+
+// wireClient returns an HTTP/1.1 client whose (single) dialed connection
+// is counted in *wire.
+func wireClient(wire **wireConn) *http.Client {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	tr.ForceAttemptHTTP2 = false
+	tr.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	dialer := &net.Dialer{}
+	tr.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		c, err := dialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		wc := &wireConn{Conn: c}
+		*wire = wc
+		return wc, nil
+	}
+	return &http.Client{Transport: tr}
+}
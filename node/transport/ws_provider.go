@@ -5,18 +5,46 @@ package transport
 
 import (
 	"context"
-
+	"net"
 	"net/http"
+	"time"
 
 	"github.com/coder/websocket"
 )
 
 // WSProvider encapsulates the logic for establishing the connection.
 type WSProvider struct {
-	server *http.Server
-	Log    LogSink
+	server  *http.Server
+	Log     LogSink
+	Metrics Metrics // optional, handed down to every WSConnection
+
+	// ConnMetrics labels the WSConnection counters per connection
+	// (see WSConnection.LabelMetrics). Off by default.
+	ConnMetrics bool
+
+	// Compression is offered to (dial) or accepted from (listen) the peer.
+	// Peers without support keep working uncompressed.
+	Compression          Compression
+	CompressionThreshold int // 0 = DefaultCompressionThreshold
+
+	// Wrap decorates every new connection before it is handed out, e.g.
+	// with an end-to-end security layer (see transport/secure). It runs
+	// below the app-level compression. An error drops the connection.
+	Wrap ConnWrapper
+
+	// HandshakeTimeout bounds Wrap, so a peer that never answers the
+	// handshake cannot hold a connection. 0 = DefaultHandshakeTimeout.
+	HandshakeTimeout time.Duration
+
+	// ReadLimit is the maximum size of a single incoming message, also
+	// after gzip decompression. 0 keeps DefaultReadLimit; large payloads
+	// should be sent as rpc attachments, which are chunked below it.
+	ReadLimit int64
 }
 
+// DefaultHandshakeTimeout is the default WSProvider.HandshakeTimeout.
+const DefaultHandshakeTimeout = 10 * time.Second
+
 func NewWSProvider(logger LogSink) *WSProvider {
 	p := &WSProvider{}
 	if logger == nil {
@@ -30,24 +58,70 @@ func NewWSProvider(logger LogSink) *WSProvider {
 // Server is waiting for a connection (server-side)
 // We use a channel to reconnect after the upgrade
 func (p *WSProvider) Listen(ctx context.Context, addr string, found chan<- Connection) error {
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return p.Serve(ctx, ln, found)
+}
+
+// Serve is Listen on an already bound listener, e.g. to know the port
+// before the server runs. Dials succeed as soon as ln is bound.
+func (p *WSProvider) Serve(ctx context.Context, ln net.Listener, found chan<- Connection) error {
+	addr := ln.Addr().String()
 	p.Log.With("addr", addr).Info("WebSocket Server startet...")
 
 	mux := http.NewServeMux()
 
 	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
-		c, err := websocket.Accept(w, r, &websocket.AcceptOptions{
+		opts := &websocket.AcceptOptions{
 			InsecureSkipVerify: true,
-		})
+			CompressionMode:    websocket.CompressionDisabled,
+		}
+		gzipped := false
+		switch p.Compression {
+		case CompressionDeflate:
+			opts.CompressionMode = websocket.CompressionContextTakeover
+			opts.CompressionThreshold = p.threshold()
+		case CompressionGzip:
+			// Only if the client offered it, older peers stay uncompressed.
+			if r.Header.Get(HeaderCompression) == "gzip" {
+				w.Header().Set(HeaderCompression, "gzip")
+				gzipped = true
+			}
+		}
+
+		c, err := websocket.Accept(w, r, opts)
+		if err != nil {
+			return
+		}
+		if p.ReadLimit > 0 {
+			c.SetReadLimit(p.ReadLimit)
+		}
+		m := MetricsOrNoop(p.Metrics)
+		m.Counter(MetricWSAccepted, 1)
+
+		ws := &WSConnection{Conn: c, ID: r.RemoteAddr, Metrics: p.Metrics, LabelMetrics: p.ConnMetrics, wire: wireConnFrom(r)}
+		conn, err := p.wrap(r.Context(), ws, gzipped)
 		if err != nil {
+			p.Log.With("remote", r.RemoteAddr).With("error", err).Warn("Connection rejected")
 			return
 		}
+
 		// Send new connection to the main inbox
-		found <- &WSConnection{Conn: c}
+		found <- conn
 	})
 
 	p.server = &http.Server{
-		Addr:    addr,
-		Handler: mux,
+		Addr:        addr,
+		Handler:     mux,
+		ConnContext: withWireConn,
+	}
+	if p.Compression == CompressionDeflate {
+		ln = wireListener{ln}
 	}
 
 	// A goroutine that waits for the context to be terminated.
@@ -57,17 +131,80 @@ func (p *WSProvider) Listen(ctx context.Context, addr string, found chan<- Conne
 		p.server.Shutdown(context.Background())
 	}()
 
-	//ListenAndServe blocks here until Shutdown() is called.
-	return p.server.ListenAndServe()
+	// Serve blocks here until Shutdown() is called.
+	return p.server.Serve(ln)
 
 }
 
 // Dial connects to a server (client side)
 func (p *WSProvider) Dial(ctx context.Context, url string) (Connection, error) {
 	p.Log.With("url", url).Info("Dial...")
-	c, _, err := websocket.Dial(ctx, url, nil)
+	m := MetricsOrNoop(p.Metrics)
+
+	opts := &websocket.DialOptions{
+		CompressionMode: websocket.CompressionDisabled,
+		HTTPHeader:      http.Header{},
+	}
+	var wire *wireConn
+	switch p.Compression {
+	case CompressionDeflate:
+		opts.CompressionMode = websocket.CompressionContextTakeover
+		opts.CompressionThreshold = p.threshold()
+		opts.HTTPClient = wireClient(&wire)
+	case CompressionGzip:
+		opts.HTTPHeader.Set(HeaderCompression, "gzip")
+	}
+
+	c, resp, err := websocket.Dial(ctx, url, opts)
 	if err != nil {
+		m.Counter(MetricWSDials, 1, "result", "error")
 		return nil, err
 	}
-	return &WSConnection{Conn: c}, nil
+	m.Counter(MetricWSDials, 1, "result", "ok")
+	if p.ReadLimit > 0 {
+		c.SetReadLimit(p.ReadLimit)
+	}
+	// The server confirms the codec only if it supports it.
+	gzipped := p.Compression == CompressionGzip && resp != nil && resp.Header.Get(HeaderCompression) == "gzip"
+	ws := &WSConnection{Conn: c, ID: url, Metrics: p.Metrics, LabelMetrics: p.ConnMetrics, wire: wire}
+	return p.wrap(ctx, ws, gzipped)
+}
+
+func (p *WSProvider) wrap(ctx context.Context, ws *WSConnection, gzipped bool) (Connection, error) {
+	var conn Connection = ws
+	if p.Wrap != nil {
+		timeout := p.HandshakeTimeout
+		if timeout <= 0 {
+			timeout = DefaultHandshakeTimeout
+		}
+		hctx, cancel := context.WithTimeout(ctx, timeout)
+		wrapped, err := p.Wrap(hctx, conn)
+		cancel()
+		if err != nil {
+			ws.Close("handshake failed")
+			return nil, err
+		}
+		conn = wrapped
+	}
+	if gzipped {
+		cc := NewCompressedConnection(conn, p.threshold())
+		cc.MaxSize = p.readLimit()
+		conn = cc
+	}
+	return conn, nil
+}
+
+// readLimit is the effective ReadLimit.
+func (p *WSProvider) readLimit() int64 {
+	if p.ReadLimit > 0 {
+		return p.ReadLimit
+	}
+	return DefaultReadLimit
+}
+
+func (p *WSProvider) threshold() int {
+	if p.CompressionThreshold > 0 {
+		return p.CompressionThreshold
+	}
+	return DefaultCompressionThreshold
 }
@@ -0,0 +1,55 @@
+// Copyright 2026 Georg Hagn
+// SPDX-License-Identifier: Apache-2.0
+
+package transport
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWSProvider_HandshakeTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addr := freeAddr(t)
+	server := NewWSProvider(nil)
+	server.HandshakeTimeout = 50 * time.Millisecond
+	wrapErr := make(chan error, 1)
+	server.Wrap = func(ctx context.Context, conn Connection) (Connection, error) {
+		// The client never sends its hello.
+		_, err := conn.Receive(ctx)
+		wrapErr <- err
+		return nil, err
+	}
+	found := make(chan Connection, 1)
+	go server.Listen(ctx, addr, found)
+
+	client := NewWSProvider(nil)
+	var err error
+	for i := 0; i < 50; i++ {
+		if _, err = client.Dial(ctx, "ws://"+addr+"/ws"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-wrapErr:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected the handshake to time out, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handshake did not time out")
+	}
+	select {
+	case <-found:
+		t.Error("Connection without handshake was handed out")
+	default:
+	}
+}
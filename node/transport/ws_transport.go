@@ -5,20 +5,77 @@ package transport
 
 import (
 	"context"
+	"sync/atomic"
 
 	"github.com/coder/websocket"
 )
 
 type WSConnection struct {
 	Conn *websocket.Conn
+
+	// ID identifies the connection (remote address or dial url).
+	ID      string
+	Metrics Metrics
+
+	// LabelMetrics adds a "conn"=ID label to the message and byte
+	// counters. Every peer address then becomes its own series, so it
+	// is meant for a handful of long-lived connections only.
+	LabelMetrics bool
+
+	wire *wireConn // counts the raw connection (permessage-deflate only)
+
+	sent, received, rawSent, rawReceived atomic.Uint64
+}
+
+// Stats implements StatsReporter. With CompressionDeflate the wire
+// sizes are measured on the network connection (including websocket
+// framing), otherwise they equal the message sizes.
+func (w *WSConnection) Stats() ConnStats {
+	st := ConnStats{
+		MessagesSent:     w.sent.Load(),
+		MessagesReceived: w.received.Load(),
+		RawBytesSent:     w.rawSent.Load(),
+		RawBytesReceived: w.rawReceived.Load(),
+	}
+	st.WireBytesSent, st.WireBytesRecv = st.RawBytesSent, st.RawBytesReceived
+	if w.wire != nil {
+		st.WireBytesSent, st.WireBytesRecv = w.wire.written.Load(), w.wire.read.Load()
+	}
+	return st
+}
+
+func (w *WSConnection) labels() []string {
+	if w.LabelMetrics {
+		return []string{"conn", w.ID}
+	}
+	return nil
 }
 
 func (w *WSConnection) Send(ctx context.Context, data []byte) error {
-	return w.Conn.Write(ctx, websocket.MessageText, data)
+	typ := websocket.MessageText
+	if IsBinaryFrame(data) {
+		typ = websocket.MessageBinary
+	}
+	if err := w.Conn.Write(ctx, typ, data); err != nil {
+		return err
+	}
+	w.sent.Add(1)
+	w.rawSent.Add(uint64(len(data)))
+	m := MetricsOrNoop(w.Metrics)
+	m.Counter(MetricWSMessagesSent, 1, w.labels()...)
+	m.Counter(MetricWSBytesSent, float64(len(data)), w.labels()...)
+	return nil
 }
 
 func (w *WSConnection) Receive(ctx context.Context) ([]byte, error) {
 	_, data, err := w.Conn.Read(ctx)
+	if err == nil {
+		w.received.Add(1)
+		w.rawReceived.Add(uint64(len(data)))
+		m := MetricsOrNoop(w.Metrics)
+		m.Counter(MetricWSMessagesRecv, 1, w.labels()...)
+		m.Counter(MetricWSBytesReceived, float64(len(data)), w.labels()...)
+	}
 	return data, err
 }
 